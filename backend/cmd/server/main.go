@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -35,17 +36,53 @@ func main() {
 	hub := service.NewHub()
 	go hub.Run()
 
-	marketService := service.NewMarketService(hub, config.Polygon.APIKey)
-	playerService := service.NewPlayerService()
-
+	marketProvider, err := service.NewMarketDataProvider(config.Market, config.Polygon.APIKey)
+	if err != nil {
+		log.Fatal("Failed to create market data provider: ", err)
+	}
+	var aggregateCache service.AggregateRepository
+	if config.Market.CacheEnabled {
+		aggregateCache = store
+	}
+	marketService := service.NewMarketService(hub, marketProvider, aggregateCache, config.Market)
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	roundManager := service.NewRoundManager(ctx, hub, marketService, playerService)
+	// The history writers get their own context so that, on shutdown, they
+	// outlive the round loop and the server and save everything recorded.
+	historyCtx, stopHistory := context.WithCancel(context.Background())
+	var historyWriters sync.WaitGroup
+	historyWriters.Add(2)
+
+	tradeHistory := service.NewTradeHistory(store)
+	go func() {
+		defer historyWriters.Done()
+		tradeHistory.Run(historyCtx)
+	}()
+
+	playerService := service.NewPlayerService(config.Trading, tradeHistory)
+	orderService := service.NewOrderService(playerService)
+	quoteStream, err := service.NewQuoteStream(config.Rounds.Live, config.Polygon.APIKey)
+	if err != nil {
+		log.Fatal("Failed to create quote stream: ", err)
+	}
+
+	roundHistory := service.NewRoundHistory(store)
+	go func() {
+		defer historyWriters.Done()
+		roundHistory.Run(historyCtx)
+	}()
+
+	roundManager, err := service.NewRoundManager(ctx, hub, marketService, playerService, orderService, quoteStream, roundHistory, config)
+	if err != nil {
+		log.Fatal("Failed to create round manager: ", err)
+	}
 	go roundManager.Run()
 
-	handler := handler.NewHandler(hub, roundManager, authService, config, playerService)
+	careerService := service.NewCareerService(store, store, store)
+
+	handler := handler.NewHandler(hub, roundManager, authService, config, playerService, orderService, roundHistory, tradeHistory, careerService)
 	router := router.NewRouter(handler, config)
 
 	// Create server
@@ -77,8 +114,12 @@ func main() {
 
 	// Shutdown server
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+		log.Println("Server forced to shutdown:", err)
 	}
 
+	// Save the trades and rounds still queued
+	stopHistory()
+	historyWriters.Wait()
+
 	log.Println("Server exited")
 }
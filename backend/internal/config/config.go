@@ -3,6 +3,7 @@ package config
 import (
 	"bytes"
 	"os"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -14,7 +15,11 @@ type Config struct {
 	Polygon struct {
 		APIKey string `mapstructure:"api_key"`
 	} `mapstructure:"polygon"`
-	Server struct {
+	Market  MarketConfig  `mapstructure:"market"`
+	Rounds  RoundsConfig  `mapstructure:"rounds"`
+	Assets  []AssetConfig `mapstructure:"assets"`
+	Trading TradingConfig `mapstructure:"trading"`
+	Server  struct {
 		Port string `mapstructure:"port"`
 	} `mapstructure:"server"`
 	JWT struct {
@@ -23,6 +28,216 @@ type Config struct {
 	} `mapstructure:"jwt"`
 }
 
+// MarketConfig selects where historical market data is loaded from.
+type MarketConfig struct {
+	Provider string `mapstructure:"provider"`
+	DataDir  string `mapstructure:"data_dir"`
+	// CacheEnabled stores fetched bars in Postgres and serves repeated windows from there.
+	CacheEnabled bool `mapstructure:"cache_enabled"`
+	// FallbackDir holds curated JSON series, in the file provider layout, used
+	// when the provider is unavailable and the cache cannot serve a window.
+	FallbackDir string        `mapstructure:"fallback_dir"`
+	Retry       RetryConfig   `mapstructure:"retry"`
+	Breaker     BreakerConfig `mapstructure:"breaker"`
+}
+
+type RetryConfig struct {
+	MaxAttempts int           `mapstructure:"max_attempts"`
+	BaseDelay   time.Duration `mapstructure:"base_delay"`
+	MaxDelay    time.Duration `mapstructure:"max_delay"`
+}
+
+// BreakerConfig opens the provider circuit after FailureThreshold consecutive
+// failures and keeps it open for Cooldown.
+type BreakerConfig struct {
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	Cooldown         time.Duration `mapstructure:"cooldown"`
+}
+
+// AssetConfig is one entry of the asset pool rounds are drawn from. Weight is
+// relative to the other entries.
+type AssetConfig struct {
+	Ticker string  `mapstructure:"ticker"`
+	Name   string  `mapstructure:"name"`
+	Class  string  `mapstructure:"class"`
+	Weight float64 `mapstructure:"weight"`
+}
+
+// TradingConfig controls leverage and liquidation. The leverage cap and
+// funding rates are the same for every asset, since anything that varied by
+// asset would give the asset away before the round reveals it. MaintenanceMargin is the
+// fraction of a position's current notional its equity must stay above.
+// Limits are the trading rules of rounds whose format sets none.
+type TradingConfig struct {
+	MaxLeverage       float64       `mapstructure:"max_leverage"`
+	MaintenanceMargin float64       `mapstructure:"maintenance_margin"`
+	Costs             CostsConfig   `mapstructure:"costs"`
+	Limits            LimitsConfig  `mapstructure:"limits"`
+	Funding           FundingConfig `mapstructure:"funding"`
+}
+
+// FundingConfig is what open positions pay to stay open, in basis points of
+// their notional per hour of game time, so an hourly replay bar is charged one
+// hour and a 5-minute bar a twelfth of that; negative rates are credited.
+// Longs pay LongBpsPerHour and shorts ShortBpsPerHour. While both sides hold
+// positions, the side with more notional also pays ImbalanceBpsPerHour scaled
+// by the imbalance, and the other side receives it.
+type FundingConfig struct {
+	LongBpsPerHour      float64 `mapstructure:"long_bps_per_hour"`
+	ShortBpsPerHour     float64 `mapstructure:"short_bps_per_hour"`
+	ImbalanceBpsPerHour float64 `mapstructure:"imbalance_bps_per_hour"`
+}
+
+// LimitsConfig is the trading rules of a round. MaxTrades is the number of
+// positions and entry orders each player may open, MinHold how long a position
+// must be held before it can be closed by hand, and OrderCooldown the minimum
+// time between a player's orders. Zero disables a rule.
+type LimitsConfig struct {
+	MaxTrades     int           `mapstructure:"max_trades"`
+	MinHold       time.Duration `mapstructure:"min_hold"`
+	OrderCooldown time.Duration `mapstructure:"order_cooldown"`
+}
+
+// CostsConfig is the execution cost model, in basis points. Market fills pay
+// half of SpreadBps, SizeImpactBps of slippage per starting balance of
+// notional, and up to CrowdImpactBps when every open position is on the same
+// side as the trade. Every fill pays TakerFeeBps on its notional.
+type CostsConfig struct {
+	TakerFeeBps    float64 `mapstructure:"taker_fee_bps"`
+	SpreadBps      float64 `mapstructure:"spread_bps"`
+	SizeImpactBps  float64 `mapstructure:"size_impact_bps"`
+	CrowdImpactBps float64 `mapstructure:"crowd_impact_bps"`
+}
+
+// RoundsConfig controls how the data for each round is produced.
+type RoundsConfig struct {
+	// SyntheticRatio is the share of rounds, between 0 and 1, that replay a
+	// generated series instead of historical market data.
+	SyntheticRatio float64         `mapstructure:"synthetic_ratio"`
+	Synthetic      SyntheticConfig `mapstructure:"synthetic"`
+	// PrefetchDepth is the number of upcoming rounds kept loaded in the background.
+	PrefetchDepth int `mapstructure:"prefetch_depth"`
+	// ReplayBars is the number of bars replayed during the live phase and
+	// HistoryCandles the number of candles shown before it.
+	ReplayBars     int                 `mapstructure:"replay_bars"`
+	HistoryCandles int                 `mapstructure:"history_candles"`
+	Formats        []RoundFormatConfig `mapstructure:"formats"`
+	Quality        QualityConfig       `mapstructure:"quality"`
+	Live           LiveConfig          `mapstructure:"live"`
+	// Disguise shifts timestamps to a synthetic epoch and rescales prices so
+	// the historical window cannot be identified until cooldown.
+	Disguise bool `mapstructure:"disguise"`
+}
+
+// LiveConfig enables live rounds, which stream the current market from a
+// quote feed instead of replaying history. Feed is polygon or websocket; an
+// empty feed disables live rounds. URL is the websocket feed's address.
+type LiveConfig struct {
+	Ratio float64 `mapstructure:"ratio"`
+	Feed  string  `mapstructure:"feed"`
+	URL   string  `mapstructure:"url"`
+}
+
+// QualityConfig controls the validation a round's series must pass before the
+// round is accepted. Repair is forward_fill, interpolate or reject. Gaps of up
+// to MaxGapFillBars bars are filled; a series needing repairs on more than
+// MaxRepairRatio of its bars is rejected. OutlierThreshold is in robust
+// standard deviations of the bar-to-bar return.
+type QualityConfig struct {
+	Repair             string  `mapstructure:"repair"`
+	MaxGapFillBars     int     `mapstructure:"max_gap_fill_bars"`
+	MaxRepairRatio     float64 `mapstructure:"max_repair_ratio"`
+	OutlierThreshold   float64 `mapstructure:"outlier_threshold"`
+	MaxZeroVolumeRatio float64 `mapstructure:"max_zero_volume_ratio"`
+	MinReplayBars      int     `mapstructure:"min_replay_bars"`
+}
+
+// RoundFormatConfig pairs the resolution replayed during the live phase
+// (minute, 5m, 15m, hour) with the candle timeframe shown on the chart
+// (1h, 4h, day, week). Each round picks one format by weight.
+// Limits, if set, replace trading.limits for rounds of this format.
+type RoundFormatConfig struct {
+	Replay  string        `mapstructure:"replay"`
+	Candles string        `mapstructure:"candles"`
+	Weight  float64       `mapstructure:"weight"`
+	Limits  *LimitsConfig `mapstructure:"limits"`
+}
+
+// SyntheticConfig parameterizes the synthetic price generator. Drift, volatility
+// and rates are annualized. Model is gbm, jump_diffusion, regime_switching or
+// mixed; the last two need at least two Regimes. A non-zero Seed makes every
+// synthetic round replay the same series, which is useful for tests.
+type SyntheticConfig struct {
+	Model         string                  `mapstructure:"model"`
+	Seed          uint64                  `mapstructure:"seed"`
+	StartPrice    float64                 `mapstructure:"start_price"`
+	Drift         float64                 `mapstructure:"drift"`
+	Volatility    float64                 `mapstructure:"volatility"`
+	JumpIntensity float64                 `mapstructure:"jump_intensity"`
+	JumpMean      float64                 `mapstructure:"jump_mean"`
+	JumpStdDev    float64                 `mapstructure:"jump_std_dev"`
+	BaseVolume    float64                 `mapstructure:"base_volume"`
+	Regimes       []SyntheticRegimeConfig `mapstructure:"regimes"`
+}
+
+type SyntheticRegimeConfig struct {
+	Drift      float64 `mapstructure:"drift"`
+	Volatility float64 `mapstructure:"volatility"`
+	SwitchRate float64 `mapstructure:"switch_rate"`
+}
+
+func setDefaults() {
+	viper.SetDefault("market.retry.max_attempts", 4)
+	viper.SetDefault("market.retry.base_delay", "500ms")
+	viper.SetDefault("market.retry.max_delay", "8s")
+	viper.SetDefault("market.breaker.failure_threshold", 5)
+	viper.SetDefault("market.breaker.cooldown", "1m")
+	viper.SetDefault("assets", []map[string]any{
+		{"ticker": "X:BTCUSD", "name": "Bitcoin", "class": "crypto", "weight": 1.0},
+	})
+	viper.SetDefault("trading.max_leverage", 10.0)
+	viper.SetDefault("trading.maintenance_margin", 0.005)
+	viper.SetDefault("trading.costs.taker_fee_bps", 5.0)
+	viper.SetDefault("trading.costs.spread_bps", 4.0)
+	viper.SetDefault("trading.costs.size_impact_bps", 0.5)
+	viper.SetDefault("trading.costs.crowd_impact_bps", 5.0)
+	viper.SetDefault("trading.funding.long_bps_per_hour", 0.0)
+	viper.SetDefault("trading.funding.short_bps_per_hour", 0.0)
+	viper.SetDefault("trading.funding.imbalance_bps_per_hour", 0.5)
+	viper.SetDefault("trading.limits.max_trades", 0)
+	viper.SetDefault("trading.limits.min_hold", 0)
+	viper.SetDefault("trading.limits.order_cooldown", 500*time.Millisecond)
+	viper.SetDefault("rounds.synthetic_ratio", 0.0)
+	viper.SetDefault("rounds.prefetch_depth", 2)
+	viper.SetDefault("rounds.disguise", true)
+	viper.SetDefault("rounds.replay_bars", 240)
+	viper.SetDefault("rounds.history_candles", 730)
+	viper.SetDefault("rounds.quality.repair", "forward_fill")
+	viper.SetDefault("rounds.quality.max_gap_fill_bars", 3)
+	viper.SetDefault("rounds.quality.max_repair_ratio", 0.05)
+	viper.SetDefault("rounds.quality.outlier_threshold", 8.0)
+	viper.SetDefault("rounds.quality.max_zero_volume_ratio", 0.2)
+	viper.SetDefault("rounds.quality.min_replay_bars", 30)
+	viper.SetDefault("rounds.live.ratio", 0.0)
+	viper.SetDefault("rounds.live.feed", "")
+	viper.SetDefault("rounds.formats", []map[string]any{
+		{"replay": "hour", "candles": "day", "weight": 1.0},
+	})
+	viper.SetDefault("rounds.synthetic.model", "mixed")
+	viper.SetDefault("rounds.synthetic.start_price", 100.0)
+	viper.SetDefault("rounds.synthetic.drift", 0.05)
+	viper.SetDefault("rounds.synthetic.volatility", 0.6)
+	viper.SetDefault("rounds.synthetic.jump_intensity", 12.0)
+	viper.SetDefault("rounds.synthetic.jump_mean", 0.0)
+	viper.SetDefault("rounds.synthetic.jump_std_dev", 0.05)
+	viper.SetDefault("rounds.synthetic.base_volume", 1000.0)
+	viper.SetDefault("rounds.synthetic.regimes", []map[string]any{
+		{"drift": 0.8, "volatility": 0.45, "switch_rate": 6.0},
+		{"drift": -0.9, "volatility": 0.8, "switch_rate": 8.0},
+		{"drift": 0.0, "volatility": 0.25, "switch_rate": 5.0},
+	})
+}
+
 func LoadConfig() (*Config, error) {
 	configPath := os.Getenv("CONFIG_PATH")
 
@@ -34,6 +249,7 @@ func LoadConfig() (*Config, error) {
 	expandedContent := os.ExpandEnv(string(content))
 
 	viper.SetConfigType("yaml")
+	setDefaults()
 
 	if err := viper.ReadConfig(bytes.NewBufferString(expandedContent)); err != nil {
 		return nil, err
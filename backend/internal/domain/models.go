@@ -18,6 +18,43 @@ type PriceData struct {
 	Volume float64 `json:"volume"`
 }
 
+type Timespan string
+
+const (
+	TimespanMinute Timespan = "minute"
+	TimespanHour   Timespan = "hour"
+	TimespanDay    Timespan = "day"
+	TimespanWeek   Timespan = "week"
+)
+
+type AssetClass string
+
+const (
+	AssetClassCrypto    AssetClass = "crypto"
+	AssetClassFX        AssetClass = "fx"
+	AssetClassIndex     AssetClass = "index"
+	AssetClassEquity    AssetClass = "equity"
+	AssetClassSynthetic AssetClass = "synthetic"
+)
+
+type Asset struct {
+	Ticker string     `json:"ticker"`
+	Name   string     `json:"name"`
+	Class  AssetClass `json:"class"`
+}
+
+// AggregateKey identifies a series of bars for one ticker at one resolution.
+type AggregateKey struct {
+	Ticker     string
+	Multiplier int
+	Timespan   Timespan
+}
+
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
 type Phase string
 
 const (
@@ -26,6 +63,15 @@ const (
 	Closed Phase = "closed"
 )
 
+// RoundType is how a round's Live phase is fed: replaying a historical or
+// synthetic series, or streaming the current market from a quote feed.
+type RoundType string
+
+const (
+	RoundTypeReplay RoundType = "replay"
+	RoundTypeLive   RoundType = "live"
+)
+
 type PositionType string
 
 const (
@@ -33,33 +79,252 @@ const (
 	PositionTypeShort PositionType = "short"
 )
 
+// Position is an open trade. Margin is the balance committed to it and
+// Quantity is sized to Margin times Leverage at the entry price. PnlPercentage
+// is relative to Margin. The position is liquidated once the price reaches
+// LiquidationPrice, which is zero for positions that cannot be liquidated.
+// StopLoss and TakeProfit are optional exit levels; zero means not set, and
+// TrailingStop is nil unless the position has one. Costs are the execution
+// costs paid so far, and Pnl is net of them.
 type Position struct {
-	Quantity      float64      `json:"quantity"`
-	Type          PositionType `json:"type"`
-	EntryPrice    float64      `json:"entryPrice"`
-	EntryTime     time.Time    `json:"entryTime"`
-	Pnl           float64      `json:"pnl"`
-	PnlPercentage float64      `json:"pnlPercentage"`
+	ID               string         `json:"id"`
+	Quantity         float64        `json:"quantity"`
+	Type             PositionType   `json:"type"`
+	EntryPrice       float64        `json:"entryPrice"`
+	EntryTime        time.Time      `json:"entryTime"`
+	Leverage         float64        `json:"leverage"`
+	Margin           float64        `json:"margin"`
+	LiquidationPrice float64        `json:"liquidationPrice"`
+	StopLoss         float64        `json:"stopLoss"`
+	TakeProfit       float64        `json:"takeProfit"`
+	TrailingStop     *TrailingStop  `json:"trailingStop"`
+	Costs            ExecutionCosts `json:"costs"`
+	Pnl              float64        `json:"pnl"`
+	PnlPercentage    float64        `json:"pnlPercentage"`
+}
+
+// TrailingStop follows the best price reached since it was set, trailing it by
+// Distance or by Percent of it. Level is the current stop price, which only
+// ever moves in the position's favour.
+type TrailingStop struct {
+	Distance float64 `json:"distance,omitempty"`
+	Percent  float64 `json:"percent,omitempty"`
+	Level    float64 `json:"level"`
+}
+
+// ExecutionCosts breaks down what a position paid to trade and to stay open.
+// Spread and Slippage are already reflected in the entry and exit prices;
+// Fees and Funding are charged on top. Funding is negative when the position
+// was credited more than it paid.
+type ExecutionCosts struct {
+	Fees     float64 `json:"fees"`
+	Spread   float64 `json:"spread"`
+	Slippage float64 `json:"slippage"`
+	Funding  float64 `json:"funding"`
 }
 
+func (c ExecutionCosts) Add(other ExecutionCosts) ExecutionCosts {
+	return ExecutionCosts{
+		Fees:     c.Fees + other.Fees,
+		Spread:   c.Spread + other.Spread,
+		Slippage: c.Slippage + other.Slippage,
+		Funding:  c.Funding + other.Funding,
+	}
+}
+
+// Charged is the part of the costs taken out of PnL rather than the fill price.
+func (c ExecutionCosts) Charged() float64 {
+	return c.Fees + c.Funding
+}
+
+type CloseReason string
+
+const (
+	CloseReasonManual       CloseReason = "manual"
+	CloseReasonLiquidation  CloseReason = "liquidation"
+	CloseReasonStopLoss     CloseReason = "stop_loss"
+	CloseReasonTakeProfit   CloseReason = "take_profit"
+	CloseReasonReverse      CloseReason = "reverse"
+	CloseReasonTrailingStop CloseReason = "trailing_stop"
+	CloseReasonSettlement   CloseReason = "settlement"
+)
+
 type ClosedPosition struct {
 	Position
-	ExitPrice float64   `json:"exitPrice"`
-	ExitTime  time.Time `json:"exitTime"`
+	ExitPrice   float64     `json:"exitPrice"`
+	ExitTime    time.Time   `json:"exitTime"`
+	CloseReason CloseReason `json:"closeReason"`
 }
 
+type OrderType string
+
+const (
+	// OrderTypeLimit enters at Price or better: a long below the market, a
+	// short above it.
+	OrderTypeLimit OrderType = "limit"
+	// OrderTypeStop enters once the market breaks through Price: a long above
+	// the market, a short below it.
+	OrderTypeStop OrderType = "stop"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusFilled    OrderStatus = "filled"
+	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusExpired   OrderStatus = "expired"
+)
+
+// Order is a resting entry order. Margin is reserved from the player's balance
+// while the order is pending and becomes the margin of the position it opens.
+type Order struct {
+	ID            string       `json:"id"`
+	Type          OrderType    `json:"type"`
+	Side          PositionType `json:"side"`
+	Price         float64      `json:"price"`
+	Margin        float64      `json:"margin"`
+	Leverage      float64      `json:"leverage"`
+	StopLoss      float64      `json:"stopLoss"`
+	TakeProfit    float64      `json:"takeProfit"`
+	TrailDistance float64      `json:"trailingDistance,omitempty"`
+	TrailPercent  float64      `json:"trailingPercent,omitempty"`
+	Status        OrderStatus  `json:"status"`
+	CreatedAt     time.Time    `json:"createdAt"`
+}
+
+// PlayerState is a player's session for the current round. Trades counts the
+// positions and entry orders the player opened and LastOrderAt is when they
+// last traded, for the round's trading limits.
 type PlayerState struct {
-	PlayerId string `json:"playerId"`
-	Username string `json:"username"`
+	PlayerId    string    `json:"playerId"`
+	Username    string    `json:"username"`
+	Trades      int       `json:"trades"`
+	LastOrderAt time.Time `json:"-"`
+	// Reserved is the margin held by the player's pending orders, already
+	// taken out of Balance.
+	Reserved float64 `json:"-"`
 	BasePlayerState
 }
 
 type BasePlayerState struct {
 	Balance         float64          `json:"balance"`
-	ActivePosition  *Position        `json:"activePosition"`
+	ActivePositions []*Position      `json:"activePositions"`
 	ClosedPositions []ClosedPosition `json:"closedPositions"`
 }
 
+// Round is the record of a round, kept up to date as it moves through its
+// phases. From and To bound the market data it replayed, or the time it
+// streamed for live rounds. FinalPrice is the displayed price open positions
+// were settled at; dividing by PriceScale gives the real price. Participants
+// counts the players who traded.
+type Round struct {
+	ID               string     `json:"id"`
+	Type             RoundType  `json:"type"`
+	Asset            Asset      `json:"asset"`
+	Synthetic        bool       `json:"synthetic"`
+	Seed             uint64     `json:"seed,string,omitempty"`
+	CandleTimeframe  string     `json:"candleTimeframe"`
+	ReplayResolution string     `json:"replayResolution"`
+	From             time.Time  `json:"from"`
+	To               time.Time  `json:"to"`
+	Phase            Phase      `json:"phase"`
+	LobbyAt          time.Time  `json:"lobbyAt"`
+	LiveAt           *time.Time `json:"liveAt"`
+	ClosedAt         *time.Time `json:"closedAt"`
+	FinalPrice       float64    `json:"finalPrice"`
+	PriceScale       float64    `json:"priceScale"`
+	Participants     int        `json:"participants"`
+}
+
+// TradeAction is whether a trade opened or closed a position.
+type TradeAction string
+
+const (
+	TradeActionOpen  TradeAction = "open"
+	TradeActionClose TradeAction = "close"
+)
+
+// Trade is one fill in a player's durable trade history: a position being
+// opened or closed. Price is the fill price and Fees the fee paid on the
+// fill. Pnl, Funding and CloseReason are only set when the position closed,
+// and cover the whole position.
+type Trade struct {
+	ID          string       `json:"id"`
+	PlayerID    string       `json:"playerId"`
+	RoundID     string       `json:"roundId"`
+	PositionID  string       `json:"positionId"`
+	Action      TradeAction  `json:"action"`
+	Type        PositionType `json:"type"`
+	Price       float64      `json:"price"`
+	Quantity    float64      `json:"quantity"`
+	Leverage    float64      `json:"leverage"`
+	Margin      float64      `json:"margin"`
+	Fees        float64      `json:"fees"`
+	Funding     float64      `json:"funding,omitempty"`
+	Pnl         float64      `json:"pnl,omitempty"`
+	CloseReason CloseReason  `json:"closeReason,omitempty"`
+	Time        time.Time    `json:"time"`
+}
+
+// PlayerResult is a player's final outcome for a round, once every open
+// position has been settled. Pnl is the change in balance over the round and
+// ReturnPercentage is relative to the starting balance. Players with the same
+// balance share a rank.
+type PlayerResult struct {
+	PlayerId         string  `json:"playerId"`
+	Username         string  `json:"username"`
+	Rank             int     `json:"rank"`
+	Balance          float64 `json:"balance"`
+	Pnl              float64 `json:"pnl"`
+	ReturnPercentage float64 `json:"returnPercentage"`
+	Trades           int     `json:"trades"`
+}
+
+// RoundResult is a player's persisted result for one round they traded in.
+// Players is the number of players ranked in that round.
+type RoundResult struct {
+	RoundID string `json:"roundId"`
+	PlayerResult
+	Players   int       `json:"players"`
+	SettledAt time.Time `json:"settledAt"`
+}
+
+// TradeTally counts a player's closed positions on one side and how many of
+// them made money.
+type TradeTally struct {
+	Trades int
+	Wins   int
+}
+
+// CareerStats aggregates a player's round results over time. A round is won
+// when it ends in profit. Rates and returns are percentages. MaxDrawdown is
+// the largest fall of the cumulative PnL from its peak, and Consistency is
+// the average return divided by its standard deviation. Hit rates are the
+// share of closed long and short positions that made money.
+type CareerStats struct {
+	RoundsPlayed  int          `json:"roundsPlayed"`
+	Wins          int          `json:"wins"`
+	WinRate       float64      `json:"winRate"`
+	AverageReturn float64      `json:"averageReturn"`
+	BestRound     *RoundResult `json:"bestRound"`
+	TotalPnl      float64      `json:"totalPnl"`
+	MaxDrawdown   float64      `json:"maxDrawdown"`
+	Consistency   float64      `json:"consistency"`
+	LongTrades    int          `json:"longTrades"`
+	LongHitRate   float64      `json:"longHitRate"`
+	ShortTrades   int          `json:"shortTrades"`
+	ShortHitRate  float64      `json:"shortHitRate"`
+}
+
+// PlayerProfile is a player's career: their lifetime stats and most recent
+// rounds.
+type PlayerProfile struct {
+	Player
+	Stats        CareerStats   `json:"stats"`
+	RecentRounds []RoundResult `json:"recentRounds"`
+}
+
 type LeaderboardPlayer struct {
 	PlayerId      string  `json:"playerId"`
 	Username      string  `json:"username"`
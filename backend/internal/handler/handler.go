@@ -6,19 +6,27 @@ import (
 )
 
 type Handler struct {
-	Hub          *service.Hub
-	RoundManager *service.RoundManager
+	Hub           *service.Hub
+	RoundManager  *service.RoundManager
 	PlayerService *service.PlayerService
-	AuthService  *service.AuthService
-	Config       *config.Config
+	OrderService  *service.OrderService
+	RoundHistory  *service.RoundHistory
+	TradeHistory  *service.TradeHistory
+	CareerService *service.CareerService
+	AuthService   *service.AuthService
+	Config        *config.Config
 }
 
-func NewHandler(hub *service.Hub, roundManager *service.RoundManager, authService *service.AuthService, config *config.Config, playerService *service.PlayerService) *Handler {
+func NewHandler(hub *service.Hub, roundManager *service.RoundManager, authService *service.AuthService, config *config.Config, playerService *service.PlayerService, orderService *service.OrderService, roundHistory *service.RoundHistory, tradeHistory *service.TradeHistory, careerService *service.CareerService) *Handler {
 	return &Handler{
-		Hub:          hub,
-		RoundManager: roundManager,
-		AuthService:  authService,
-		Config:       config,
+		Hub:           hub,
+		RoundManager:  roundManager,
+		AuthService:   authService,
+		Config:        config,
 		PlayerService: playerService,
+		OrderService:  orderService,
+		RoundHistory:  roundHistory,
+		TradeHistory:  tradeHistory,
+		CareerService: careerService,
 	}
-}
\ No newline at end of file
+}
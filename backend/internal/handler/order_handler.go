@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"tradeoff/backend/internal/domain"
+	"tradeoff/backend/internal/helpers"
+	"tradeoff/backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// orderRequest is a limit or stop entry order. Sizing and exits work as in
+// positionRequest.
+type orderRequest struct {
+	Type             domain.OrderType    `json:"type"`
+	Side             domain.PositionType `json:"side"`
+	Price            float64             `json:"price"`
+	Amount           float64             `json:"amount"`
+	Fraction         float64             `json:"fraction"`
+	Leverage         float64             `json:"leverage"`
+	StopLoss         float64             `json:"stopLoss"`
+	TakeProfit       float64             `json:"takeProfit"`
+	TrailingDistance float64             `json:"trailingDistance"`
+	TrailingPercent  float64             `json:"trailingPercent"`
+	Tick             uint64              `json:"tick"`
+}
+
+func (h *Handler) PlaceOrder(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from context
+	userID, ok := r.Context().Value("userId").(string)
+	if !ok {
+		helpers.RespondWithError(w, helpers.NewCustomError("Unauthorized", http.StatusUnauthorized))
+		return
+	}
+
+	var orderReq orderRequest
+	if err := json.NewDecoder(r.Body).Decode(&orderReq); err != nil {
+		helpers.RespondWithError(w, helpers.NewCustomError("Invalid request body", http.StatusBadRequest))
+		return
+	}
+
+	req := service.OrderRequest{
+		Type:  orderReq.Type,
+		Side:  orderReq.Side,
+		Price: orderReq.Price,
+		Size: service.PositionSize{
+			Amount:   orderReq.Amount,
+			Fraction: orderReq.Fraction,
+			Leverage: orderReq.Leverage,
+		},
+		Exits: service.PositionExits{
+			StopLoss:      orderReq.StopLoss,
+			TakeProfit:    orderReq.TakeProfit,
+			TrailDistance: orderReq.TrailingDistance,
+			TrailPercent:  orderReq.TrailingPercent,
+		},
+	}
+	var order *domain.Order
+	err := h.RoundManager.Trade(orderReq.Tick, func(quote service.Quote) error {
+		var err error
+		order, err = h.OrderService.PlaceOrder(userID, req, quote.Price, h.RoundManager.MaxLeverage())
+		return err
+	})
+	if err != nil {
+		helpers.RespondWithError(w, tradeError(err))
+		return
+	}
+
+	helpers.RespondWithJSON(w, http.StatusCreated, order)
+}
+
+func (h *Handler) GetOrders(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from context
+	userID, ok := r.Context().Value("userId").(string)
+	if !ok {
+		helpers.RespondWithError(w, helpers.NewCustomError("Unauthorized", http.StatusUnauthorized))
+		return
+	}
+
+	helpers.RespondWithJSON(w, http.StatusOK, h.OrderService.GetPendingOrders(userID))
+}
+
+func (h *Handler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from context
+	userID, ok := r.Context().Value("userId").(string)
+	if !ok {
+		helpers.RespondWithError(w, helpers.NewCustomError("Unauthorized", http.StatusUnauthorized))
+		return
+	}
+
+	order, err := h.OrderService.CancelOrder(userID, chi.URLParam(r, "orderID"))
+	if err != nil {
+		helpers.RespondWithError(w, tradeError(err))
+		return
+	}
+
+	helpers.RespondWithJSON(w, http.StatusOK, order)
+}
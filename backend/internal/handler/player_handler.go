@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"tradeoff/backend/internal/helpers"
+	"tradeoff/backend/internal/service"
+)
+
+func (h *Handler) GetPlayerInfo(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from context
+	userID, ok := r.Context().Value("userId").(string)
+	if !ok {
+		helpers.RespondWithError(w, helpers.NewCustomError("Unauthorized", http.StatusUnauthorized))
+		return
+	}
+
+	profile, err := h.CareerService.GetProfile(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrPlayerNotFound) {
+			err = helpers.NewCustomError(err.Error(), http.StatusNotFound)
+		}
+		helpers.RespondWithError(w, err)
+		return
+	}
+
+	helpers.RespondWithJSON(w, http.StatusOK, profile)
+}
@@ -2,14 +2,86 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"tradeoff/backend/internal/domain"
 	"tradeoff/backend/internal/helpers"
 	"tradeoff/backend/internal/service"
 )
 
+// positionRequest sizes the position by amount or by fraction of balance.
+// With neither set the whole balance is committed. Leverage defaults to 1x.
+// A trailing stop trails by an absolute distance or by a percentage.
+//
+// Every trading request may carry the Tick of the price the client saw, in
+// which case it is refused once a newer bar has arrived.
 type positionRequest struct {
-	Type domain.PositionType `json:"type"`
+	Type             domain.PositionType `json:"type"`
+	Amount           float64             `json:"amount"`
+	Fraction         float64             `json:"fraction"`
+	Leverage         float64             `json:"leverage"`
+	StopLoss         float64             `json:"stopLoss"`
+	TakeProfit       float64             `json:"takeProfit"`
+	TrailingDistance float64             `json:"trailingDistance"`
+	TrailingPercent  float64             `json:"trailingPercent"`
+	Tick             uint64              `json:"tick"`
+}
+
+// positionExitsRequest replaces all exits; zero clears a level and a zero
+// distance and percent remove the trailing stop.
+type positionExitsRequest struct {
+	PositionID       string  `json:"positionId"`
+	StopLoss         float64 `json:"stopLoss"`
+	TakeProfit       float64 `json:"takeProfit"`
+	TrailingDistance float64 `json:"trailingDistance"`
+	TrailingPercent  float64 `json:"trailingPercent"`
+	Tick             uint64  `json:"tick"`
+}
+
+// reversePositionResponse is the position a reverse closed and the one it
+// opened in the opposite direction.
+type reversePositionResponse struct {
+	Closed *domain.ClosedPosition `json:"closed"`
+	Opened *domain.Position       `json:"opened"`
+}
+
+// closePositionRequest names the position to close or reverse. It may be
+// omitted when the player holds a single position.
+type closePositionRequest struct {
+	PositionID string `json:"positionId"`
+	Tick       uint64 `json:"tick"`
+}
+
+// tradeError maps position and order errors from the service layer to HTTP errors.
+func tradeError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrSessionNotFound),
+		errors.Is(err, service.ErrPositionNotFound),
+		errors.Is(err, service.ErrOrderNotFound):
+		return helpers.NewCustomError(err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrTooManyPositions),
+		errors.Is(err, service.ErrTradingClosed),
+		errors.Is(err, service.ErrStaleQuote),
+		errors.Is(err, service.ErrTradeLimitReached),
+		errors.Is(err, service.ErrMinHoldTime):
+		return helpers.NewCustomError(err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrOrderCooldown):
+		return helpers.NewCustomError(err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, service.ErrPriceUnavailable):
+		return helpers.NewCustomError(err.Error(), http.StatusServiceUnavailable)
+	case errors.Is(err, service.ErrNoActivePosition),
+		errors.Is(err, service.ErrNoBalance),
+		errors.Is(err, service.ErrInvalidPositionSize),
+		errors.Is(err, service.ErrBelowMinNotional),
+		errors.Is(err, service.ErrInsufficientFunds),
+		errors.Is(err, service.ErrInvalidLeverage),
+		errors.Is(err, service.ErrInvalidExitLevel),
+		errors.Is(err, service.ErrInvalidOrder):
+		return helpers.NewCustomError(err.Error(), http.StatusBadRequest)
+	default:
+		return err
+	}
 }
 
 func (h *Handler) CreatePosition(w http.ResponseWriter, r *http.Request) {
@@ -27,10 +99,25 @@ func (h *Handler) CreatePosition(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	currentPrice := h.RoundManager.GetCurrentPrice()
-	position, err := h.PlayerService.CreatePosition(userID, positionReq.Type, currentPrice)
+	size := service.PositionSize{
+		Amount:   positionReq.Amount,
+		Fraction: positionReq.Fraction,
+		Leverage: positionReq.Leverage,
+	}
+	exits := service.PositionExits{
+		StopLoss:      positionReq.StopLoss,
+		TakeProfit:    positionReq.TakeProfit,
+		TrailDistance: positionReq.TrailingDistance,
+		TrailPercent:  positionReq.TrailingPercent,
+	}
+	var position *domain.Position
+	err := h.RoundManager.Trade(positionReq.Tick, func(quote service.Quote) error {
+		var err error
+		position, err = h.PlayerService.CreatePosition(userID, positionReq.Type, quote.Price, size, exits, h.RoundManager.MaxLeverage())
+		return err
+	})
 	if err != nil {
-		helpers.RespondWithError(w, err)
+		helpers.RespondWithError(w, tradeError(err))
 		return
 	}
 
@@ -57,10 +144,19 @@ func (h *Handler) ClosePosition(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	currentPrice := h.RoundManager.GetCurrentPrice()
-	_, err := h.PlayerService.ClosePosition(userID, currentPrice)
+	// The body is optional, so an empty one is not an error.
+	var closeReq closePositionRequest
+	if err := json.NewDecoder(r.Body).Decode(&closeReq); err != nil && !errors.Is(err, io.EOF) {
+		helpers.RespondWithError(w, helpers.NewCustomError("Invalid request body", http.StatusBadRequest))
+		return
+	}
+
+	err := h.RoundManager.Trade(closeReq.Tick, func(quote service.Quote) error {
+		_, err := h.PlayerService.ClosePosition(userID, closeReq.PositionID, quote.Price)
+		return err
+	})
 	if err != nil {
-		helpers.RespondWithError(w, err)
+		helpers.RespondWithError(w, tradeError(err))
 		return
 	}
 
@@ -78,3 +174,82 @@ func (h *Handler) ClosePosition(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *Handler) SetPositionExits(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from context
+	userID, ok := r.Context().Value("userId").(string)
+	if !ok {
+		helpers.RespondWithError(w, helpers.NewCustomError("Unauthorized", http.StatusUnauthorized))
+		return
+	}
+
+	var exitsReq positionExitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&exitsReq); err != nil {
+		helpers.RespondWithError(w, helpers.NewCustomError("Invalid request body", http.StatusBadRequest))
+		return
+	}
+
+	exits := service.PositionExits{
+		StopLoss:      exitsReq.StopLoss,
+		TakeProfit:    exitsReq.TakeProfit,
+		TrailDistance: exitsReq.TrailingDistance,
+		TrailPercent:  exitsReq.TrailingPercent,
+	}
+	var position *domain.Position
+	err := h.RoundManager.Trade(exitsReq.Tick, func(quote service.Quote) error {
+		var err error
+		position, err = h.PlayerService.SetPositionExits(userID, exitsReq.PositionID, exits, quote.Price)
+		return err
+	})
+	if err != nil {
+		helpers.RespondWithError(w, tradeError(err))
+		return
+	}
+
+	helpers.RespondWithJSON(w, http.StatusOK, position)
+}
+
+func (h *Handler) ReversePosition(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from context
+	userID, ok := r.Context().Value("userId").(string)
+	if !ok {
+		helpers.RespondWithError(w, helpers.NewCustomError("Unauthorized", http.StatusUnauthorized))
+		return
+	}
+
+	// The body is optional, so an empty one is not an error.
+	var reverseReq closePositionRequest
+	if err := json.NewDecoder(r.Body).Decode(&reverseReq); err != nil && !errors.Is(err, io.EOF) {
+		helpers.RespondWithError(w, helpers.NewCustomError("Invalid request body", http.StatusBadRequest))
+		return
+	}
+
+	var closed *domain.ClosedPosition
+	var opened *domain.Position
+	err := h.RoundManager.Trade(reverseReq.Tick, func(quote service.Quote) error {
+		var err error
+		closed, opened, err = h.PlayerService.ReversePosition(userID, reverseReq.PositionID, quote.Price)
+		return err
+	})
+	if err != nil {
+		helpers.RespondWithError(w, tradeError(err))
+		return
+	}
+
+	// Send count update to all clients
+	longPositions, shortPositions := h.PlayerService.GetPositionsCount()
+	countUpdate := service.WsMessage{
+		Type: service.WsMsgTypeCountUpdate,
+		Data: service.CountUpdatePayload{
+			LongPositions:  longPositions,
+			ShortPositions: shortPositions,
+			TotalPlayers:   h.PlayerService.GetPlayerCount(),
+		},
+	}
+	h.Hub.Broadcast <- countUpdate
+
+	helpers.RespondWithJSON(w, http.StatusCreated, reversePositionResponse{
+		Closed: closed,
+		Opened: opened,
+	})
+}
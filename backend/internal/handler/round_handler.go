@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"tradeoff/backend/internal/helpers"
+	"tradeoff/backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// queryInt parses an optional non-negative integer query parameter.
+func queryInt(r *http.Request, name string) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, helpers.NewCustomError("Invalid "+name, http.StatusBadRequest)
+	}
+	return n, nil
+}
+
+func (h *Handler) ListRounds(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		helpers.RespondWithError(w, err)
+		return
+	}
+	offset, err := queryInt(r, "offset")
+	if err != nil {
+		helpers.RespondWithError(w, err)
+		return
+	}
+
+	rounds, err := h.RoundHistory.ListRounds(limit, offset)
+	if err != nil {
+		helpers.RespondWithError(w, err)
+		return
+	}
+
+	helpers.RespondWithJSON(w, http.StatusOK, rounds)
+}
+
+func (h *Handler) GetRound(w http.ResponseWriter, r *http.Request) {
+	round, err := h.RoundHistory.GetRound(chi.URLParam(r, "roundID"))
+	if err != nil {
+		if errors.Is(err, service.ErrRoundNotFound) {
+			err = helpers.NewCustomError(err.Error(), http.StatusNotFound)
+		}
+		helpers.RespondWithError(w, err)
+		return
+	}
+
+	helpers.RespondWithJSON(w, http.StatusOK, round)
+}
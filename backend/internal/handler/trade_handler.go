@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+	"tradeoff/backend/internal/helpers"
+)
+
+func (h *Handler) ListTrades(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from context
+	userID, ok := r.Context().Value("userId").(string)
+	if !ok {
+		helpers.RespondWithError(w, helpers.NewCustomError("Unauthorized", http.StatusUnauthorized))
+		return
+	}
+
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		helpers.RespondWithError(w, err)
+		return
+	}
+	offset, err := queryInt(r, "offset")
+	if err != nil {
+		helpers.RespondWithError(w, err)
+		return
+	}
+
+	trades, err := h.TradeHistory.ListTrades(userID, limit, offset)
+	if err != nil {
+		helpers.RespondWithError(w, err)
+		return
+	}
+
+	helpers.RespondWithJSON(w, http.StatusOK, trades)
+}
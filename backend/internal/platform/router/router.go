@@ -36,9 +36,17 @@ func NewRouter(h *handler.Handler, config *config.Config) *chi.Mux {
 	appRouter.Post("/login", h.Login)
 	appRouter.Post("/refresh", h.RefreshToken)
 
-	// appRouter.With(middleware.AuthMiddleware(h.Config)).Get("/player", h.GetPlayerInfo)
+	appRouter.With(middleware.AuthMiddleware(h.Config)).Get("/player", h.GetPlayerInfo)
 	appRouter.With(middleware.AuthMiddleware(h.Config)).Post("/position", h.CreatePosition)
 	appRouter.With(middleware.AuthMiddleware(h.Config)).Post("/close-position", h.ClosePosition)
+	appRouter.With(middleware.AuthMiddleware(h.Config)).Post("/reverse-position", h.ReversePosition)
+	appRouter.With(middleware.AuthMiddleware(h.Config)).Put("/position/exits", h.SetPositionExits)
+	appRouter.With(middleware.AuthMiddleware(h.Config)).Get("/orders", h.GetOrders)
+	appRouter.With(middleware.AuthMiddleware(h.Config)).Post("/orders", h.PlaceOrder)
+	appRouter.With(middleware.AuthMiddleware(h.Config)).Delete("/orders/{orderID}", h.CancelOrder)
+	appRouter.With(middleware.AuthMiddleware(h.Config)).Get("/trades", h.ListTrades)
+	appRouter.With(middleware.AuthMiddleware(h.Config)).Get("/rounds", h.ListRounds)
+	appRouter.With(middleware.AuthMiddleware(h.Config)).Get("/rounds/{roundID}", h.GetRound)
 
 	router.Mount("/api", appRouter)
 
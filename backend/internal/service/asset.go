@@ -0,0 +1,30 @@
+package service
+
+import (
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+// DefaultAsset is used when the configured asset pool is empty.
+var DefaultAsset = domain.Asset{
+	Ticker: "X:BTCUSD",
+	Name:   "Bitcoin",
+	Class:  domain.AssetClassCrypto,
+}
+
+func assetFromConfig(cfg config.AssetConfig) domain.Asset {
+	return domain.Asset{
+		Ticker: cfg.Ticker,
+		Name:   cfg.Name,
+		Class:  domain.AssetClass(cfg.Class),
+	}
+}
+
+// pickAsset draws an asset from the pool with probability proportional to its weight.
+func pickAsset(pool []config.AssetConfig) domain.Asset {
+	asset, ok := pickWeighted(pool, func(a config.AssetConfig) float64 { return a.Weight })
+	if !ok {
+		return DefaultAsset
+	}
+	return assetFromConfig(asset)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"tradeoff/backend/internal/domain"
+)
+
+// Resolution is a bar size such as 5 minutes or 4 hours. It is used both for
+// the bars replayed during the live phase and for the candles shown on the chart.
+type Resolution struct {
+	Multiplier int
+	Timespan   domain.Timespan
+}
+
+var (
+	ResolutionMinute   = Resolution{Multiplier: 1, Timespan: domain.TimespanMinute}
+	Resolution5Minute  = Resolution{Multiplier: 5, Timespan: domain.TimespanMinute}
+	Resolution15Minute = Resolution{Multiplier: 15, Timespan: domain.TimespanMinute}
+	ResolutionHour     = Resolution{Multiplier: 1, Timespan: domain.TimespanHour}
+	Resolution4Hour    = Resolution{Multiplier: 4, Timespan: domain.TimespanHour}
+	ResolutionDay      = Resolution{Multiplier: 1, Timespan: domain.TimespanDay}
+	ResolutionWeek     = Resolution{Multiplier: 1, Timespan: domain.TimespanWeek}
+)
+
+var resolutionNames = map[string]Resolution{
+	"minute": ResolutionMinute,
+	"1m":     ResolutionMinute,
+	"5m":     Resolution5Minute,
+	"15m":    Resolution15Minute,
+	"hour":   ResolutionHour,
+	"1h":     ResolutionHour,
+	"4h":     Resolution4Hour,
+	"day":    ResolutionDay,
+	"1d":     ResolutionDay,
+	"week":   ResolutionWeek,
+	"1w":     ResolutionWeek,
+}
+
+// ParseResolution parses names like "minute", "5m", "15m", "hour", "4h", "day" and "week".
+func ParseResolution(name string) (Resolution, error) {
+	resolution, ok := resolutionNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Resolution{}, fmt.Errorf("unknown resolution %q", name)
+	}
+	return resolution, nil
+}
+
+func (r Resolution) Duration() time.Duration {
+	return time.Duration(r.Multiplier) * timespanDuration(r.Timespan)
+}
+
+func (r Resolution) String() string {
+	if r.Multiplier == 0 {
+		return ""
+	}
+	switch r.Timespan {
+	case domain.TimespanMinute:
+		return fmt.Sprintf("%dm", r.Multiplier)
+	case domain.TimespanHour:
+		return fmt.Sprintf("%dh", r.Multiplier)
+	case domain.TimespanWeek:
+		return fmt.Sprintf("%dw", r.Multiplier)
+	default:
+		return fmt.Sprintf("%dd", r.Multiplier)
+	}
+}
+
+// BucketStart returns the start of the bar containing t. Buckets are aligned
+// in UTC; weekly buckets start on Sunday, as Polygon stamps its weekly bars.
+func (r Resolution) BucketStart(t time.Time) time.Time {
+	t = t.UTC()
+	if r.Timespan == domain.TimespanWeek {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -int(day.Weekday()))
+	}
+	return t.Truncate(r.Duration())
+}
+
+// query builds an aggregates query for ticker at this resolution.
+func (r Resolution) query(ticker string, from time.Time, to time.Time) AggregatesQuery {
+	return AggregatesQuery{
+		Ticker:     ticker,
+		Multiplier: r.Multiplier,
+		Timespan:   r.Timespan,
+		From:       from,
+		To:         to,
+	}
+}
+
+// aggregateCandle folds a replayed bar into the chart at the given timeframe.
+// It returns the updated chart and whether the bar updated the last candle
+// (true) or opened a new one (false).
+func aggregateCandle(chart []domain.PriceData, bar domain.PriceData, timeframe Resolution) ([]domain.PriceData, bool) {
+	bucket := timeframe.BucketStart(time.Unix(bar.Time, 0)).Unix()
+
+	if len(chart) == 0 || chart[len(chart)-1].Time < bucket {
+		candle := bar
+		candle.Time = bucket
+		return append(chart, candle), false
+	}
+
+	last := &chart[len(chart)-1]
+	last.High = max(last.High, bar.High)
+	last.Low = min(last.Low, bar.Low)
+	last.Close = bar.Close
+	last.Volume += bar.Volume
+	return chart, true
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"tradeoff/backend/internal/domain"
+)
+
+func TestBucketStart(t *testing.T) {
+	tests := []struct {
+		name       string
+		resolution Resolution
+		at         time.Time
+		want       time.Time
+	}{
+		{
+			name:       "15 minutes",
+			resolution: Resolution15Minute,
+			at:         time.Date(2024, time.June, 5, 10, 44, 59, 0, time.UTC),
+			want:       time.Date(2024, time.June, 5, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "4 hours",
+			resolution: Resolution4Hour,
+			at:         time.Date(2024, time.June, 5, 7, 59, 0, 0, time.UTC),
+			want:       time.Date(2024, time.June, 5, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "day in another time zone",
+			resolution: ResolutionDay,
+			at:         time.Date(2024, time.June, 5, 1, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60)),
+			want:       time.Date(2024, time.June, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "week from midweek",
+			resolution: ResolutionWeek,
+			at:         time.Date(2024, time.June, 5, 12, 0, 0, 0, time.UTC),
+			want:       time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "week from Sunday",
+			resolution: ResolutionWeek,
+			at:         time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC),
+			want:       time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "week from Saturday night",
+			resolution: ResolutionWeek,
+			at:         time.Date(2024, time.June, 8, 23, 59, 0, 0, time.UTC),
+			want:       time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.resolution.BucketStart(tt.at); !got.Equal(tt.want) {
+				t.Fatalf("BucketStart(%s) = %s, want %s", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregateCandle(t *testing.T) {
+	hour := func(h int) int64 {
+		return time.Date(2024, time.June, 3, h, 0, 0, 0, time.UTC).Unix()
+	}
+	bars := []domain.PriceData{
+		{Time: hour(0), Open: 10, High: 12, Low: 9, Close: 11, Volume: 1},
+		{Time: hour(1), Open: 11, High: 15, Low: 10, Close: 14, Volume: 2},
+		{Time: hour(2), Open: 14, High: 14, Low: 8, Close: 9, Volume: 3},
+		{Time: hour(4), Open: 9, High: 10, Low: 7, Close: 8, Volume: 4},
+	}
+
+	var chart []domain.PriceData
+	var updated []bool
+	for _, bar := range bars {
+		var last bool
+		chart, last = aggregateCandle(chart, bar, Resolution4Hour)
+		updated = append(updated, last)
+	}
+
+	want := []domain.PriceData{
+		{Time: hour(0), Open: 10, High: 15, Low: 8, Close: 9, Volume: 6},
+		{Time: hour(4), Open: 9, High: 10, Low: 7, Close: 8, Volume: 4},
+	}
+	if !reflect.DeepEqual(chart, want) {
+		t.Errorf("chart = %+v, want %+v", chart, want)
+	}
+	if wantUpdated := []bool{false, true, true, false}; !reflect.DeepEqual(updated, wantUpdated) {
+		t.Errorf("updated last candle = %v, want %v", updated, wantUpdated)
+	}
+}
+
+func TestAggregateCandleStampsBucketStart(t *testing.T) {
+	bar := domain.PriceData{
+		Time:  time.Date(2024, time.June, 5, 13, 0, 0, 0, time.UTC).Unix(),
+		Open:  1,
+		High:  1,
+		Low:   1,
+		Close: 1,
+	}
+	chart, updated := aggregateCandle(nil, bar, ResolutionWeek)
+	if updated || len(chart) != 1 {
+		t.Fatalf("got %d candles (updated %v), want one new candle", len(chart), updated)
+	}
+	if want := time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC).Unix(); chart[0].Time != want {
+		t.Fatalf("candle time = %d, want the week's Sunday %d", chart[0].Time, want)
+	}
+}
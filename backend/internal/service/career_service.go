@@ -0,0 +1,113 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"tradeoff/backend/internal/domain"
+)
+
+const RecentRoundsSize = 10
+
+// CareerService builds each player's career profile from their persisted
+// round results and closed trades.
+type CareerService struct {
+	players PlayerRepository
+	rounds  RoundRepository
+	trades  TradeRepository
+}
+
+func NewCareerService(players PlayerRepository, rounds RoundRepository, trades TradeRepository) *CareerService {
+	return &CareerService{
+		players: players,
+		rounds:  rounds,
+		trades:  trades,
+	}
+}
+
+// GetProfile returns the player with their lifetime stats and most recent
+// rounds, newest first, or ErrPlayerNotFound if there is no such player.
+func (s *CareerService) GetProfile(playerID string) (domain.PlayerProfile, error) {
+	player, err := s.players.FindPlayer(playerID)
+	if err != nil {
+		return domain.PlayerProfile{}, err
+	}
+	if player == nil {
+		return domain.PlayerProfile{}, fmt.Errorf("%w: %s", ErrPlayerNotFound, playerID)
+	}
+	results, err := s.rounds.ListRoundResults(playerID)
+	if err != nil {
+		return domain.PlayerProfile{}, err
+	}
+	long, short, err := s.trades.TallyClosedTrades(playerID)
+	if err != nil {
+		return domain.PlayerProfile{}, err
+	}
+
+	recent := make([]domain.RoundResult, 0, min(len(results), RecentRoundsSize))
+	for i := len(results) - 1; i >= 0 && len(recent) < RecentRoundsSize; i-- {
+		recent = append(recent, results[i])
+	}
+
+	return domain.PlayerProfile{
+		Player:       *player,
+		Stats:        careerStats(results, long, short),
+		RecentRounds: recent,
+	}, nil
+}
+
+// careerStats aggregates round results given oldest first. Consistency needs
+// at least two rounds and is zero while returns do not vary.
+func careerStats(results []domain.RoundResult, long domain.TradeTally, short domain.TradeTally) domain.CareerStats {
+	stats := domain.CareerStats{
+		RoundsPlayed: len(results),
+		LongTrades:   long.Trades,
+		LongHitRate:  hitRate(long),
+		ShortTrades:  short.Trades,
+		ShortHitRate: hitRate(short),
+	}
+	if len(results) == 0 {
+		return stats
+	}
+
+	var sumReturns, peak float64
+	for i := range results {
+		result := &results[i]
+		if result.Pnl > 0 {
+			stats.Wins++
+		}
+		sumReturns += result.ReturnPercentage
+		if stats.BestRound == nil || result.ReturnPercentage > stats.BestRound.ReturnPercentage {
+			best := *result
+			stats.BestRound = &best
+		}
+
+		stats.TotalPnl += result.Pnl
+		peak = max(peak, stats.TotalPnl)
+		stats.MaxDrawdown = max(stats.MaxDrawdown, peak-stats.TotalPnl)
+	}
+
+	rounds := float64(len(results))
+	stats.WinRate = float64(stats.Wins) / rounds * 100
+	stats.AverageReturn = sumReturns / rounds
+
+	if len(results) > 1 {
+		var variance float64
+		for _, result := range results {
+			deviation := result.ReturnPercentage - stats.AverageReturn
+			variance += deviation * deviation
+		}
+		stdDev := math.Sqrt(variance / (rounds - 1))
+		if stdDev > 0 {
+			stats.Consistency = stats.AverageReturn / stdDev
+		}
+	}
+	return stats
+}
+
+// hitRate is the percentage of trades that made money.
+func hitRate(tally domain.TradeTally) float64 {
+	if tally.Trades == 0 {
+		return 0
+	}
+	return float64(tally.Wins) / float64(tally.Trades) * 100
+}
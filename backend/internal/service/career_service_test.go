@@ -0,0 +1,198 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+	"tradeoff/backend/internal/domain"
+)
+
+func resultsWithReturns(returns ...float64) []domain.RoundResult {
+	results := make([]domain.RoundResult, 0, len(returns))
+	for i, ret := range returns {
+		results = append(results, domain.RoundResult{
+			RoundID: fmt.Sprintf("round-%d", i+1),
+			PlayerResult: domain.PlayerResult{
+				PlayerId:         "player",
+				Balance:          StartingBalance + ret,
+				Pnl:              ret,
+				ReturnPercentage: ret,
+			},
+			SettledAt: time.Date(2024, time.June, 1, 0, i, 0, 0, time.UTC),
+		})
+	}
+	return results
+}
+
+func TestCareerStats(t *testing.T) {
+	tests := []struct {
+		name            string
+		returns         []float64
+		wantWins        int
+		wantWinRate     float64
+		wantAverage     float64
+		wantBestRound   string
+		wantTotalPnl    float64
+		wantDrawdown    float64
+		wantConsistency float64
+	}{
+		{
+			name: "no rounds",
+		},
+		{
+			name:          "single round has no consistency",
+			returns:       []float64{5},
+			wantWins:      1,
+			wantWinRate:   100,
+			wantAverage:   5,
+			wantBestRound: "round-1",
+			wantTotalPnl:  5,
+		},
+		{
+			name:          "identical returns have no consistency",
+			returns:       []float64{2, 2, 2},
+			wantWins:      3,
+			wantWinRate:   100,
+			wantAverage:   2,
+			wantBestRound: "round-1",
+			wantTotalPnl:  6,
+		},
+		{
+			name:            "ties keep the earliest best round",
+			returns:         []float64{10, -4, 10},
+			wantWins:        2,
+			wantWinRate:     200.0 / 3,
+			wantAverage:     16.0 / 3,
+			wantBestRound:   "round-1",
+			wantTotalPnl:    16,
+			wantDrawdown:    4,
+			wantConsistency: (16.0 / 3) / math.Sqrt(196.0/3),
+		},
+		{
+			name:            "drawdown from a later peak",
+			returns:         []float64{10, -20, 5, -5},
+			wantWins:        2,
+			wantWinRate:     50,
+			wantAverage:     -2.5,
+			wantBestRound:   "round-1",
+			wantTotalPnl:    -10,
+			wantDrawdown:    20,
+			wantConsistency: -2.5 / math.Sqrt(175),
+		},
+		{
+			name:            "losses from the start count as drawdown",
+			returns:         []float64{-5, -5, 0},
+			wantWinRate:     0,
+			wantAverage:     -10.0 / 3,
+			wantBestRound:   "round-3",
+			wantTotalPnl:    -10,
+			wantDrawdown:    10,
+			wantConsistency: (-10.0 / 3) / math.Sqrt(25.0/3),
+		},
+	}
+
+	const tolerance = 1e-9
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := careerStats(resultsWithReturns(tt.returns...), domain.TradeTally{}, domain.TradeTally{})
+
+			if stats.RoundsPlayed != len(tt.returns) || stats.Wins != tt.wantWins {
+				t.Errorf("rounds played %d with %d wins, want %d with %d", stats.RoundsPlayed, stats.Wins, len(tt.returns), tt.wantWins)
+			}
+			checks := []struct {
+				field     string
+				got, want float64
+			}{
+				{"winRate", stats.WinRate, tt.wantWinRate},
+				{"averageReturn", stats.AverageReturn, tt.wantAverage},
+				{"totalPnl", stats.TotalPnl, tt.wantTotalPnl},
+				{"maxDrawdown", stats.MaxDrawdown, tt.wantDrawdown},
+				{"consistency", stats.Consistency, tt.wantConsistency},
+			}
+			for _, check := range checks {
+				if math.Abs(check.got-check.want) > tolerance {
+					t.Errorf("%s = %v, want %v", check.field, check.got, check.want)
+				}
+			}
+
+			switch {
+			case tt.wantBestRound == "" && stats.BestRound != nil:
+				t.Errorf("best round = %s, want none", stats.BestRound.RoundID)
+			case tt.wantBestRound != "" && (stats.BestRound == nil || stats.BestRound.RoundID != tt.wantBestRound):
+				t.Errorf("best round = %+v, want %s", stats.BestRound, tt.wantBestRound)
+			}
+		})
+	}
+}
+
+func TestCareerStatsHitRates(t *testing.T) {
+	stats := careerStats(nil, domain.TradeTally{Trades: 4, Wins: 3}, domain.TradeTally{})
+	if stats.LongTrades != 4 || stats.LongHitRate != 75 {
+		t.Errorf("long = %d trades at %v%%, want 4 at 75%%", stats.LongTrades, stats.LongHitRate)
+	}
+	if stats.ShortTrades != 0 || stats.ShortHitRate != 0 {
+		t.Errorf("short = %d trades at %v%%, want none at 0%%", stats.ShortTrades, stats.ShortHitRate)
+	}
+}
+
+type careerPlayers struct {
+	PlayerRepository
+}
+
+func (careerPlayers) FindPlayer(id string) (*domain.Player, error) {
+	if id == "unknown" {
+		return nil, nil
+	}
+	return &domain.Player{Id: id, Username: "trader"}, nil
+}
+
+type careerRounds struct {
+	RoundRepository
+	results []domain.RoundResult
+}
+
+func (r careerRounds) ListRoundResults(string) ([]domain.RoundResult, error) {
+	return r.results, nil
+}
+
+type careerTrades struct {
+	TradeRepository
+}
+
+func (careerTrades) TallyClosedTrades(string) (domain.TradeTally, domain.TradeTally, error) {
+	return domain.TradeTally{Trades: 2, Wins: 1}, domain.TradeTally{Trades: 1, Wins: 1}, nil
+}
+
+func TestGetProfile(t *testing.T) {
+	returns := make([]float64, 12)
+	for i := range returns {
+		returns[i] = float64(i)
+	}
+	careers := NewCareerService(careerPlayers{}, careerRounds{results: resultsWithReturns(returns...)}, careerTrades{})
+
+	profile, err := careers.GetProfile("player")
+	if err != nil {
+		t.Fatalf("GetProfile: %v", err)
+	}
+	if profile.Id != "player" || profile.Username != "trader" {
+		t.Errorf("player = %+v, want player/trader", profile.Player)
+	}
+	if profile.Stats.RoundsPlayed != 12 || profile.Stats.LongHitRate != 50 || profile.Stats.ShortHitRate != 100 {
+		t.Errorf("stats = %+v, want 12 rounds with 50%% long and 100%% short hit rates", profile.Stats)
+	}
+	if len(profile.RecentRounds) != RecentRoundsSize {
+		t.Fatalf("got %d recent rounds, want %d", len(profile.RecentRounds), RecentRoundsSize)
+	}
+	if first, last := profile.RecentRounds[0].RoundID, profile.RecentRounds[RecentRoundsSize-1].RoundID; first != "round-12" || last != "round-3" {
+		t.Errorf("recent rounds run from %s to %s, want round-12 to round-3", first, last)
+	}
+}
+
+func TestGetProfileUnknownPlayer(t *testing.T) {
+	careers := NewCareerService(careerPlayers{}, careerRounds{}, careerTrades{})
+	if _, err := careers.GetProfile("unknown"); !errors.Is(err, ErrPlayerNotFound) {
+		t.Fatalf("GetProfile error = %v, want %v", err, ErrPlayerNotFound)
+	}
+}
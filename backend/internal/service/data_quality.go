@@ -0,0 +1,296 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+const (
+	RepairForwardFill = "forward_fill"
+	RepairInterpolate = "interpolate"
+	// RepairReject rejects any series that would need repairing.
+	RepairReject = "reject"
+
+	DefaultMaxGapFillBars     = 3
+	DefaultMaxRepairRatio     = 0.05
+	DefaultOutlierThreshold   = 8.0
+	DefaultMaxZeroVolumeRatio = 0.2
+	DefaultMinReplayBars      = 30
+)
+
+// QualityReport summarizes the problems found in one series and what was
+// done about them. It is logged as JSON for every series a round loads.
+type QualityReport struct {
+	Ticker        string  `json:"ticker"`
+	Series        string  `json:"series"`
+	Resolution    string  `json:"resolution"`
+	InputBars     int     `json:"inputBars"`
+	OutputBars    int     `json:"outputBars"`
+	OutOfOrder    int     `json:"outOfOrder"`
+	Duplicates    int     `json:"duplicates"`
+	InvalidBars   int     `json:"invalidBars"`
+	Outliers      int     `json:"outliers"`
+	ZeroVolume    int     `json:"zeroVolume"`
+	GapsFilled    int     `json:"gapsFilled"`
+	BarsFilled    int     `json:"barsFilled"`
+	SessionBreaks int     `json:"sessionBreaks"`
+	RepairRatio   float64 `json:"repairRatio"`
+	Accepted      bool    `json:"accepted"`
+	Reason        string  `json:"reason,omitempty"`
+}
+
+// seriesValidator checks a series for gaps, duplicates, non-monotonic
+// timestamps and bad prints, repairing them where the config allows.
+type seriesValidator struct {
+	repair             string
+	maxGapFillBars     int
+	maxRepairRatio     float64
+	outlierThreshold   float64
+	maxZeroVolumeRatio float64
+}
+
+func newSeriesValidator(cfg config.QualityConfig) seriesValidator {
+	v := seriesValidator{
+		repair:             cfg.Repair,
+		maxGapFillBars:     cfg.MaxGapFillBars,
+		maxRepairRatio:     cfg.MaxRepairRatio,
+		outlierThreshold:   cfg.OutlierThreshold,
+		maxZeroVolumeRatio: cfg.MaxZeroVolumeRatio,
+	}
+	if v.repair == "" {
+		v.repair = RepairForwardFill
+	}
+	if v.maxGapFillBars <= 0 {
+		v.maxGapFillBars = DefaultMaxGapFillBars
+	}
+	if v.maxRepairRatio <= 0 {
+		v.maxRepairRatio = DefaultMaxRepairRatio
+	}
+	if v.outlierThreshold <= 0 {
+		v.outlierThreshold = DefaultOutlierThreshold
+	}
+	if v.maxZeroVolumeRatio <= 0 {
+		v.maxZeroVolumeRatio = DefaultMaxZeroVolumeRatio
+	}
+	return v
+}
+
+// validate returns the repaired series and its report. Only crypto and
+// synthetic assets trade around the clock; for the others, gaps across days
+// are treated as market closures rather than missing data.
+func (v seriesValidator) validate(series []domain.PriceData, resolution Resolution, asset domain.Asset) ([]domain.PriceData, QualityReport) {
+	continuous := asset.Class == domain.AssetClassCrypto || asset.Class == domain.AssetClassSynthetic
+	report := QualityReport{
+		Ticker:     asset.Ticker,
+		Resolution: resolution.String(),
+		InputBars:  len(series),
+	}
+
+	bars := make([]domain.PriceData, len(series))
+	copy(bars, series)
+
+	for i := 1; i < len(bars); i++ {
+		if bars[i].Time < bars[i-1].Time {
+			report.OutOfOrder++
+		}
+	}
+	sort.SliceStable(bars, func(i, j int) bool {
+		return bars[i].Time < bars[j].Time
+	})
+
+	bars = v.removeDuplicates(bars, &report)
+	bars = v.fixInvalidBars(bars, &report)
+	v.repairOutliers(bars, &report)
+	bars = v.fillGaps(bars, resolution, continuous, &report)
+
+	for _, bar := range bars {
+		if bar.Volume <= 0 {
+			report.ZeroVolume++
+		}
+	}
+	report.OutputBars = len(bars)
+
+	repairs := report.Duplicates + report.InvalidBars + report.Outliers + report.BarsFilled
+	if report.InputBars > 0 {
+		report.RepairRatio = float64(repairs) / float64(report.InputBars)
+	}
+
+	switch {
+	case len(bars) == 0:
+		report.Reason = "series is empty"
+	case v.repair == RepairReject && (repairs > 0 || report.OutOfOrder > 0):
+		report.Reason = "series needs repair and repair is disabled"
+	case report.RepairRatio > v.maxRepairRatio:
+		report.Reason = fmt.Sprintf("repair ratio %.3f exceeds %.3f", report.RepairRatio, v.maxRepairRatio)
+	// Index aggregates carry no volume at all.
+	case asset.Class != domain.AssetClassIndex && float64(report.ZeroVolume)/float64(len(bars)) > v.maxZeroVolumeRatio:
+		report.Reason = fmt.Sprintf("%d of %d bars have no volume", report.ZeroVolume, len(bars))
+	default:
+		report.Accepted = true
+	}
+	return bars, report
+}
+
+func (v seriesValidator) removeDuplicates(bars []domain.PriceData, report *QualityReport) []domain.PriceData {
+	deduped := bars[:0]
+	for _, bar := range bars {
+		if len(deduped) > 0 && deduped[len(deduped)-1].Time == bar.Time {
+			// Keep the later print for a repeated timestamp.
+			deduped[len(deduped)-1] = bar
+			report.Duplicates++
+			continue
+		}
+		deduped = append(deduped, bar)
+	}
+	return deduped
+}
+
+// fixInvalidBars drops bars with non-positive prices and widens high/low so
+// they contain the open and close.
+func (v seriesValidator) fixInvalidBars(bars []domain.PriceData, report *QualityReport) []domain.PriceData {
+	valid := bars[:0]
+	for _, bar := range bars {
+		if bar.Open <= 0 || bar.High <= 0 || bar.Low <= 0 || bar.Close <= 0 {
+			report.InvalidBars++
+			continue
+		}
+		high := max(bar.High, bar.Open, bar.Close)
+		low := min(bar.Low, bar.Open, bar.Close)
+		if high != bar.High || low != bar.Low {
+			report.InvalidBars++
+			bar.High = high
+			bar.Low = low
+		}
+		valid = append(valid, bar)
+	}
+	return valid
+}
+
+// repairOutliers replaces isolated spikes, where the close jumps away and
+// straight back by more than outlierThreshold robust standard deviations, and
+// clamps wicks that extend that far beyond the bar body.
+func (v seriesValidator) repairOutliers(bars []domain.PriceData, report *QualityReport) {
+	if len(bars) < 3 {
+		return
+	}
+
+	returns := make([]float64, 0, len(bars)-1)
+	for i := 1; i < len(bars); i++ {
+		returns = append(returns, math.Log(bars[i].Close/bars[i-1].Close))
+	}
+	sigma := robustStdDev(returns)
+	if sigma == 0 {
+		return
+	}
+	limit := v.outlierThreshold * sigma
+
+	for i := 1; i < len(bars)-1; i++ {
+		in := math.Log(bars[i].Close / bars[i-1].Close)
+		out := math.Log(bars[i+1].Close / bars[i].Close)
+		if math.Abs(in) > limit && math.Abs(out) > limit && (in > 0) != (out > 0) {
+			report.Outliers++
+			bars[i] = v.fillBar(bars[i-1], bars[i+1], bars[i].Time, 1, 2)
+			bars[i].Volume = 0
+		}
+	}
+
+	for i := range bars {
+		bodyHigh := max(bars[i].Open, bars[i].Close)
+		bodyLow := min(bars[i].Open, bars[i].Close)
+		clamped := false
+		if math.Log(bars[i].High/bodyHigh) > limit {
+			bars[i].High = bodyHigh * math.Exp(limit)
+			clamped = true
+		}
+		if math.Log(bodyLow/bars[i].Low) > limit {
+			bars[i].Low = bodyLow * math.Exp(-limit)
+			clamped = true
+		}
+		if clamped {
+			report.Outliers++
+		}
+	}
+}
+
+// fillGaps inserts bars for short runs of missing timestamps. Longer gaps, and
+// for non-continuous assets any gap spanning days, are counted as session breaks.
+func (v seriesValidator) fillGaps(bars []domain.PriceData, resolution Resolution, continuous bool, report *QualityReport) []domain.PriceData {
+	step := int64(resolution.Duration() / time.Second)
+	if len(bars) < 2 || step <= 0 || resolution.Timespan == domain.TimespanWeek {
+		return bars
+	}
+
+	filled := make([]domain.PriceData, 0, len(bars))
+	filled = append(filled, bars[0])
+	for i := 1; i < len(bars); i++ {
+		prev := bars[i-1]
+		next := bars[i]
+		missing := int((next.Time-prev.Time)/step) - 1
+
+		if missing > 0 {
+			sameDay := time.Unix(prev.Time, 0).UTC().YearDay() == time.Unix(next.Time, 0).UTC().YearDay()
+			fillable := missing <= v.maxGapFillBars && (continuous || (sameDay && resolution.Duration() < 24*time.Hour))
+			if !fillable {
+				report.SessionBreaks++
+			} else {
+				report.GapsFilled++
+				report.BarsFilled += missing
+				for k := 1; k <= missing; k++ {
+					filled = append(filled, v.fillBar(prev, next, prev.Time+int64(k)*step, k, missing+1))
+				}
+			}
+		}
+		filled = append(filled, next)
+	}
+	return filled
+}
+
+// fillBar synthesizes the k-th of n steps between prev and next using the
+// configured repair mode. Filled bars carry no volume.
+func (v seriesValidator) fillBar(prev domain.PriceData, next domain.PriceData, t int64, k int, n int) domain.PriceData {
+	price := prev.Close
+	if v.repair == RepairInterpolate {
+		price = prev.Close + (next.Open-prev.Close)*float64(k)/float64(n)
+	}
+	return domain.PriceData{
+		Time:  t,
+		Open:  price,
+		High:  price,
+		Low:   price,
+		Close: price,
+	}
+}
+
+// robustStdDev estimates the standard deviation from the median absolute
+// deviation, which a handful of bad prints cannot inflate.
+func robustStdDev(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	deviations := make([]float64, len(values))
+	for i, value := range values {
+		deviations[i] = math.Abs(value - median)
+	}
+	sort.Float64s(deviations)
+	return 1.4826 * deviations[len(deviations)/2]
+}
+
+func logQualityReport(report QualityReport) {
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		log.Printf("Error encoding data quality report: %v", err)
+		return
+	}
+	log.Printf("Data quality report: %s", encoded)
+}
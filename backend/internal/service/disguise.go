@@ -0,0 +1,77 @@
+package service
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+	"tradeoff/backend/internal/domain"
+)
+
+const (
+	week = 7 * 24 * time.Hour
+
+	// Disguised replays start somewhere in the ten years after the synthetic
+	// epoch, at a price level between disguiseMinPrice and disguiseMaxPrice.
+	disguiseEpochWeeks = 520
+	disguiseMinPrice   = 50.0
+	disguiseMaxPrice   = 5000.0
+)
+
+// disguiseEpoch is a Sunday, the start of a weekly candle. Timestamps are
+// shifted by whole weeks, which keeps daily and weekly candles aligned to
+// their boundaries.
+var disguiseEpoch = time.Date(2000, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+// priceDisguise maps a round's real bars onto a synthetic timeline and price
+// level so players cannot look up the historical window. Prices are multiplied
+// by a single factor, which leaves every return unchanged.
+type priceDisguise struct {
+	timeOffset int64
+	priceScale float64
+}
+
+// identityDisguise leaves bars unchanged.
+var identityDisguise = priceDisguise{priceScale: 1}
+
+// newPriceDisguise picks a random synthetic start week and price level for a
+// replay that really starts with firstBar.
+func newPriceDisguise(firstBar domain.PriceData) priceDisguise {
+	if firstBar.Open <= 0 {
+		return identityDisguise
+	}
+
+	syntheticStart := disguiseEpoch.Add(time.Duration(rand.IntN(disguiseEpochWeeks)) * week)
+	weekSeconds := int64(week / time.Second)
+	offsetWeeks := (syntheticStart.Unix() - firstBar.Time) / weekSeconds
+
+	logMin, logMax := math.Log(disguiseMinPrice), math.Log(disguiseMaxPrice)
+	targetPrice := math.Exp(logMin + rand.Float64()*(logMax-logMin))
+
+	return priceDisguise{
+		timeOffset: offsetWeeks * weekSeconds,
+		priceScale: targetPrice / firstBar.Open,
+	}
+}
+
+func (d priceDisguise) price(price float64) float64 {
+	return price * d.priceScale
+}
+
+func (d priceDisguise) bar(bar domain.PriceData) domain.PriceData {
+	return domain.PriceData{
+		Time:   bar.Time + d.timeOffset,
+		Open:   d.price(bar.Open),
+		High:   d.price(bar.High),
+		Low:    d.price(bar.Low),
+		Close:  d.price(bar.Close),
+		Volume: bar.Volume,
+	}
+}
+
+func (d priceDisguise) series(series []domain.PriceData) []domain.PriceData {
+	disguised := make([]domain.PriceData, len(series))
+	for i, bar := range series {
+		disguised[i] = d.bar(bar)
+	}
+	return disguised
+}
@@ -0,0 +1,26 @@
+package service
+
+import "errors"
+
+var (
+	ErrSessionNotFound     = errors.New("player session not found")
+	ErrTooManyPositions    = errors.New("player has too many open positions")
+	ErrNoActivePosition    = errors.New("player has no open positions")
+	ErrPositionNotFound    = errors.New("position not found")
+	ErrNoBalance           = errors.New("player has no balance")
+	ErrInvalidPositionSize = errors.New("invalid position size")
+	ErrBelowMinNotional    = errors.New("position is below the minimum notional")
+	ErrInsufficientFunds   = errors.New("insufficient funds")
+	ErrInvalidLeverage     = errors.New("invalid leverage")
+	ErrInvalidExitLevel    = errors.New("invalid exit level")
+	ErrInvalidOrder        = errors.New("invalid order")
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrTradingClosed       = errors.New("trading is closed outside the live phase")
+	ErrPriceUnavailable    = errors.New("no price available yet")
+	ErrStaleQuote          = errors.New("quote is older than the current tick")
+	ErrTradeLimitReached   = errors.New("no trades left this round")
+	ErrMinHoldTime         = errors.New("position has not been held long enough")
+	ErrOrderCooldown       = errors.New("orders are too frequent")
+	ErrRoundNotFound       = errors.New("round not found")
+	ErrPlayerNotFound      = errors.New("player not found")
+)
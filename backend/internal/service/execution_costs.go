@@ -0,0 +1,60 @@
+package service
+
+import (
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+const bps = 1e-4
+
+// executionKind is how an order meets the market. Market executions cross
+// the spread and pay slippage; limit executions fill at their own price and
+// only pay the fee.
+type executionKind int
+
+const (
+	executionMarket executionKind = iota
+	executionLimit
+)
+
+// costModel prices a trade's execution. Market fills happen half the spread
+// away from the mid price, plus slippage that grows with the trade's notional
+// and with how one-sided the crowd already is in the trade's direction. Every
+// fill pays a taker fee on its notional.
+type costModel struct {
+	takerFee    float64
+	halfSpread  float64
+	sizeImpact  float64
+	crowdImpact float64
+}
+
+func newCostModel(cfg config.CostsConfig) costModel {
+	return costModel{
+		takerFee:    cfg.TakerFeeBps * bps,
+		halfSpread:  cfg.SpreadBps * bps / 2,
+		sizeImpact:  cfg.SizeImpactBps * bps,
+		crowdImpact: cfg.CrowdImpactBps * bps,
+	}
+}
+
+// fill returns the execution price for trading notional at price, buying if
+// buy is set, and what the execution cost. crowdImbalance is the share of open
+// positions already on the trade's side minus the share against it.
+func (m costModel) fill(price float64, buy bool, notional float64, kind executionKind, crowdImbalance float64) (float64, domain.ExecutionCosts) {
+	if kind == executionLimit {
+		return price, domain.ExecutionCosts{Fees: notional * m.takerFee}
+	}
+
+	slippage := m.sizeImpact*notional/StartingBalance + m.crowdImpact*max(crowdImbalance, 0)
+	offset := m.halfSpread + slippage
+	fillPrice := price * (1 + offset)
+	if !buy {
+		fillPrice = price * (1 - offset)
+	}
+
+	return fillPrice, domain.ExecutionCosts{
+		Fees:     notional * fillPrice / price * m.takerFee,
+		Spread:   notional * m.halfSpread,
+		Slippage: notional * slippage,
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+func TestCostModelFill(t *testing.T) {
+	model := newCostModel(config.CostsConfig{
+		TakerFeeBps:    5,
+		SpreadBps:      4,
+		SizeImpactBps:  0.5,
+		CrowdImpactBps: 5,
+	})
+
+	tests := []struct {
+		name      string
+		buy       bool
+		notional  float64
+		kind      executionKind
+		imbalance float64
+		wantPrice float64
+		wantCosts domain.ExecutionCosts
+	}{
+		{
+			name:      "limit fill pays only the fee",
+			buy:       true,
+			notional:  1000,
+			kind:      executionLimit,
+			imbalance: 1,
+			wantPrice: 100,
+			wantCosts: domain.ExecutionCosts{Fees: 0.5},
+		},
+		{
+			name:      "market buy crosses half the spread",
+			buy:       true,
+			notional:  100,
+			kind:      executionMarket,
+			wantPrice: 100.025,
+			wantCosts: domain.ExecutionCosts{Fees: 0.0500125, Spread: 0.02, Slippage: 0.005},
+		},
+		{
+			name:      "market sell into a crowded side",
+			buy:       false,
+			notional:  100,
+			kind:      executionMarket,
+			imbalance: 0.5,
+			wantPrice: 99.95,
+			wantCosts: domain.ExecutionCosts{Fees: 0.049975, Spread: 0.02, Slippage: 0.03},
+		},
+		{
+			name:      "trading against the crowd adds no impact",
+			buy:       true,
+			notional:  100,
+			kind:      executionMarket,
+			imbalance: -0.8,
+			wantPrice: 100.025,
+			wantCosts: domain.ExecutionCosts{Fees: 0.0500125, Spread: 0.02, Slippage: 0.005},
+		},
+	}
+
+	const tolerance = 1e-9
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, costs := model.fill(100, tt.buy, tt.notional, tt.kind, tt.imbalance)
+			if math.Abs(price-tt.wantPrice) > tolerance {
+				t.Errorf("fill price = %v, want %v", price, tt.wantPrice)
+			}
+			if math.Abs(costs.Fees-tt.wantCosts.Fees) > tolerance ||
+				math.Abs(costs.Spread-tt.wantCosts.Spread) > tolerance ||
+				math.Abs(costs.Slippage-tt.wantCosts.Slippage) > tolerance {
+				t.Errorf("costs = %+v, want %+v", costs, tt.wantCosts)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"tradeoff/backend/internal/domain"
+)
+
+// FileProvider serves aggregates from JSON files on local disk so rounds can run
+// offline and in CI. Each file holds a JSON array of domain.PriceData for one
+// ticker and resolution, named <ticker>_<multiplier>_<timespan>.json with any
+// ':' in the ticker replaced by '_' (e.g. X_BTCUSD_1_hour.json).
+type FileProvider struct {
+	dataDir string
+}
+
+func NewFileProvider(dataDir string) *FileProvider {
+	return &FileProvider{
+		dataDir: dataDir,
+	}
+}
+
+func (p *FileProvider) fileName(query AggregatesQuery) string {
+	multiplier := query.Key().Multiplier
+	ticker := strings.ReplaceAll(query.Ticker, ":", "_")
+	return filepath.Join(p.dataDir, fmt.Sprintf("%s_%d_%s.json", ticker, multiplier, query.Timespan))
+}
+
+func (p *FileProvider) LoadAggregates(ctx context.Context, query AggregatesQuery) ([]domain.PriceData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	path := p.fileName(query)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading market data file: %w", err)
+	}
+
+	var series []domain.PriceData
+	if err := json.Unmarshal(content, &series); err != nil {
+		return nil, fmt.Errorf("decoding market data file %s: %w", path, err)
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Time < series[j].Time
+	})
+
+	from := query.From.Unix()
+	to := query.To.Unix()
+	priceData := []domain.PriceData{}
+	for _, bar := range series {
+		if bar.Time < from || bar.Time > to {
+			continue
+		}
+		priceData = append(priceData, bar)
+		if query.Limit != nil && len(priceData) >= *query.Limit {
+			break
+		}
+	}
+
+	return priceData, nil
+}
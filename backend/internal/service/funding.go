@@ -0,0 +1,58 @@
+package service
+
+import (
+	"time"
+	"tradeoff/backend/internal/domain"
+)
+
+// ApplyFunding charges every open position funding for elapsed, the game time
+// the bar just processed covers, on its notional at price. Longs and shorts
+// pay their base rates. While both sides hold positions, the side with more
+// notional also pays the imbalance rate scaled by the notional imbalance, and
+// what it pays is shared among the other side in proportion to notional, so
+// nothing is created or lost. Funding accrues into each position's costs, so
+// it comes out of PnL and moves the liquidation price.
+func (s *PlayerService) ApplyFunding(price float64, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hours := elapsed.Hours()
+	longRate := s.funding.LongBpsPerHour * bps * hours
+	shortRate := s.funding.ShortBpsPerHour * bps * hours
+
+	var longNotional, shortNotional float64
+	for _, session := range s.playerSessions {
+		for _, position := range session.ActivePositions {
+			if position.Type == domain.PositionTypeShort {
+				shortNotional += position.Quantity * price
+			} else {
+				longNotional += position.Quantity * price
+			}
+		}
+	}
+	if longNotional > 0 && shortNotional > 0 {
+		imbalance := (longNotional - shortNotional) / (longNotional + shortNotional)
+		paid := s.funding.ImbalanceBpsPerHour * bps * hours * imbalance
+		if imbalance > 0 {
+			longRate += paid
+			shortRate -= paid * longNotional / shortNotional
+		} else {
+			shortRate -= paid
+			longRate += paid * shortNotional / longNotional
+		}
+	}
+	if longRate == 0 && shortRate == 0 {
+		return
+	}
+
+	for _, session := range s.playerSessions {
+		for _, position := range session.ActivePositions {
+			rate := longRate
+			if position.Type == domain.PositionTypeShort {
+				rate = shortRate
+			}
+			position.Costs.Funding += position.Quantity * price * rate
+			position.LiquidationPrice = s.liquidationPrice(position)
+		}
+	}
+}
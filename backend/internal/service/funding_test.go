@@ -0,0 +1,113 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+// openTestPositions opens a position for each player at price 100 with
+// margin and leverage 1, on a service without execution costs.
+func openTestPositions(t *testing.T, s *PlayerService, positions map[string]domain.PositionType, margin map[string]float64) {
+	t.Helper()
+	for player, positionType := range positions {
+		s.GetPlayerSessionOrCreate(player, &player)
+		if _, err := s.CreatePosition(player, positionType, 100, PositionSize{Amount: margin[player], Leverage: 1}, PositionExits{}, 1); err != nil {
+			t.Fatalf("CreatePosition(%s): %v", player, err)
+		}
+	}
+}
+
+func fundingPaid(s *PlayerService) map[string]float64 {
+	paid := map[string]float64{}
+	for player, session := range s.GetAllSessions() {
+		for _, position := range session.ActivePositions {
+			paid[player] += position.Costs.Funding
+		}
+	}
+	return paid
+}
+
+func TestApplyFunding(t *testing.T) {
+	tests := []struct {
+		name      string
+		funding   config.FundingConfig
+		positions map[string]domain.PositionType
+		margin    map[string]float64
+		elapsed   time.Duration
+		want      map[string]float64
+	}{
+		{
+			name:      "lone side pays no imbalance",
+			funding:   config.FundingConfig{ImbalanceBpsPerHour: 100},
+			positions: map[string]domain.PositionType{"alice": domain.PositionTypeLong},
+			margin:    map[string]float64{"alice": 100},
+			elapsed:   time.Hour,
+			want:      map[string]float64{"alice": 0},
+		},
+		{
+			name:    "crowded side pays the other",
+			funding: config.FundingConfig{ImbalanceBpsPerHour: 100},
+			positions: map[string]domain.PositionType{
+				"alice": domain.PositionTypeLong,
+				"bob":   domain.PositionTypeLong,
+				"carol": domain.PositionTypeShort,
+			},
+			margin:  map[string]float64{"alice": 100, "bob": 50, "carol": 50},
+			elapsed: time.Hour,
+			// Longs hold 150 against 50, an imbalance of 0.5, so longs pay
+			// 0.5% of 150 and the short receives all of it.
+			want: map[string]float64{"alice": 0.5, "bob": 0.25, "carol": -0.75},
+		},
+		{
+			name:    "base rates scale with game time",
+			funding: config.FundingConfig{LongBpsPerHour: 10, ShortBpsPerHour: 20},
+			positions: map[string]domain.PositionType{
+				"alice": domain.PositionTypeLong,
+				"carol": domain.PositionTypeShort,
+			},
+			margin:  map[string]float64{"alice": 100, "carol": 100},
+			elapsed: 15 * time.Minute,
+			want:    map[string]float64{"alice": 0.025, "carol": 0.05},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewPlayerService(config.TradingConfig{Funding: tt.funding}, NewTradeHistory(nil))
+			openTestPositions(t, s, tt.positions, tt.margin)
+
+			s.ApplyFunding(100, tt.elapsed)
+
+			paid := fundingPaid(s)
+			for player, want := range tt.want {
+				if math.Abs(paid[player]-want) > 1e-9 {
+					t.Errorf("%s paid %v funding, want %v", player, paid[player], want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyFundingConservesImbalancePayments(t *testing.T) {
+	s := NewPlayerService(config.TradingConfig{Funding: config.FundingConfig{ImbalanceBpsPerHour: 40}}, NewTradeHistory(nil))
+	openTestPositions(t, s,
+		map[string]domain.PositionType{"alice": domain.PositionTypeLong, "bob": domain.PositionTypeShort, "carol": domain.PositionTypeShort, "dave": domain.PositionTypeShort},
+		map[string]float64{"alice": 20, "bob": 70, "carol": 30, "dave": 55},
+	)
+
+	s.ApplyFunding(103, 5*time.Hour)
+
+	var total float64
+	for _, paid := range fundingPaid(s) {
+		total += paid
+	}
+	if math.Abs(total) > 1e-9 {
+		t.Fatalf("imbalance funding sums to %v, want 0", total)
+	}
+	if paid := fundingPaid(s)["alice"]; paid >= 0 {
+		t.Fatalf("the lone long paid %v, want a credit from the crowded shorts", paid)
+	}
+}
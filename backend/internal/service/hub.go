@@ -17,6 +17,10 @@ const (
 
 	WsMsgTypePnlUpdate         WsMsgType = "pnl_update"
 	WsMsgTypeLeaderboardUpdate WsMsgType = "leaderboard_update"
+	WsMsgTypeLiquidation       WsMsgType = "liquidation"
+	WsMsgTypeOrderFilled       WsMsgType = "order_filled"
+	WsMsgTypeOrderUpdate       WsMsgType = "order_update"
+	WsMsgTypeRoundResult       WsMsgType = "round_result"
 )
 
 type WsMessage struct {
@@ -28,10 +32,18 @@ type WsMessage struct {
 // It contains everything a client needs to render the game from scratch.
 type GameStatePayload struct {
 	RoundID             string             `json:"roundId"`
+	RoundType           domain.RoundType   `json:"roundType"`
 	ChartData           []domain.PriceData `json:"chartData"`
+	CandleTimeframe     string             `json:"candleTimeframe"`
+	ReplayResolution    string             `json:"replayResolution"`
+	MaxLeverage         float64            `json:"maxLeverage"`
+	Tick                uint64             `json:"tick"`
+	TradeLimits         TradeLimits        `json:"tradeLimits"`
+	TradesRemaining     *int               `json:"tradesRemaining"`
 	TotalPnl            float64            `json:"pnl"`
 	ActivePnl           float64            `json:"activePnl"`
 	ActivePnlPercentage float64            `json:"activePnlPercentage"`
+	PendingOrders       []domain.Order     `json:"pendingOrders"`
 	PhaseChangePayload
 	CountUpdatePayload
 	domain.BasePlayerState
@@ -41,6 +53,21 @@ type GameStatePayload struct {
 type PhaseChangePayload struct {
 	Phase   domain.Phase `json:"phase"`
 	EndTime time.Time    `json:"endTime"` // Unix milliseconds
+	Reveal  *RoundReveal `json:"reveal,omitempty"`
+}
+
+// RoundReveal discloses what the round was actually trading and how its chart
+// was disguised. It is only sent once the round reaches cooldown. Displayed
+// prices are real prices times PriceScale, and displayed timestamps are real
+// Unix times plus TimeOffset seconds.
+type RoundReveal struct {
+	Asset      domain.Asset `json:"asset"`
+	Synthetic  bool         `json:"synthetic"`
+	Seed       uint64       `json:"seed,string,omitempty"`
+	From       time.Time    `json:"from"`
+	To         time.Time    `json:"to"`
+	PriceScale float64      `json:"priceScale"`
+	TimeOffset int64        `json:"timeOffset"`
 }
 
 // CountUpdatePayload is the data for the 'count_update' message.
@@ -51,17 +78,60 @@ type CountUpdatePayload struct {
 }
 
 // PnlUpdatePayload is the data for the 'pnl_update' message.
-// This is sent directly to a single player.
+// This is sent directly to a single player. TrailingStops holds the current
+// level of each trailing stop, keyed by position ID, and TradesRemaining is
+// only set when the round limits trades.
 type PnlUpdatePayload struct {
-	TotalPnl            float64 `json:"pnl"`
-	Balance             float64 `json:"balance"`
-	ActivePnl           float64 `json:"activePnl"`
-	ActivePnlPercentage float64 `json:"activePnlPercentage"`
+	TotalPnl            float64            `json:"pnl"`
+	Balance             float64            `json:"balance"`
+	ActivePnl           float64            `json:"activePnl"`
+	ActivePnlPercentage float64            `json:"activePnlPercentage"`
+	TrailingStops       map[string]float64 `json:"trailingStops,omitempty"`
+	TradesRemaining     *int               `json:"tradesRemaining,omitempty"`
+}
+
+// LiquidationPayload is the data for the 'liquidation' message.
+// This is sent directly to the liquidated player.
+type LiquidationPayload struct {
+	Position domain.ClosedPosition `json:"position"`
+	Balance  float64               `json:"balance"`
+}
+
+// OrderFilledPayload is the data for the 'order_filled' message, sent
+// directly to a player whose stop-loss or take-profit closed their position.
+type OrderFilledPayload struct {
+	Position domain.ClosedPosition `json:"position"`
+	Balance  float64               `json:"balance"`
+}
+
+// OrderUpdatePayload is the data for the 'order_update' message, sent
+// directly to a player whose entry order was filled or expired. Position is
+// the position a filled order opened.
+type OrderUpdatePayload struct {
+	Order    domain.Order     `json:"order"`
+	Position *domain.Position `json:"position,omitempty"`
+	Balance  float64          `json:"balance"`
+}
+
+// RoundResultPayload is the data for the 'round_result' message, sent directly
+// to each player when the round is settled. Podium is the top of the final
+// ranking and Result is the receiving player's own outcome, with Settled
+// holding the positions that were closed at the final price.
+type RoundResultPayload struct {
+	RoundID string                  `json:"roundId"`
+	Podium  []domain.PlayerResult   `json:"podium"`
+	Players int                     `json:"players"`
+	Result  domain.PlayerResult     `json:"result"`
+	Settled []domain.ClosedPosition `json:"settled"`
 }
 
+// PriceUpdate is the data for the 'price_update' message. UpdateLast is false
+// when the replayed bar opened a new candle and true when it updated the last one.
+// Tick numbers the round's live bars from 1.
 type PriceUpdate struct {
 	PriceData  domain.PriceData `json:"priceData"`
 	UpdateLast bool             `json:"updateLast"`
+	Tick       uint64           `json:"tick"`
 }
 type DirectMessage struct {
 	Client  *Client   `json:"client"`
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+const (
+	MarketProviderPolygon = "polygon"
+	MarketProviderFile    = "file"
+)
+
+// AggregatesQuery describes a window of OHLCV bars to load for a ticker.
+type AggregatesQuery struct {
+	Ticker     string
+	Multiplier int
+	Timespan   domain.Timespan
+	From       time.Time
+	To         time.Time
+	Limit      *int
+}
+
+// Key returns the series the query reads from.
+func (q AggregatesQuery) Key() domain.AggregateKey {
+	multiplier := q.Multiplier
+	if multiplier <= 0 {
+		multiplier = 1
+	}
+	return domain.AggregateKey{
+		Ticker:     q.Ticker,
+		Multiplier: multiplier,
+		Timespan:   q.Timespan,
+	}
+}
+
+// BarDuration returns the length of a single bar at the query's resolution.
+func (q AggregatesQuery) BarDuration() time.Duration {
+	key := q.Key()
+	return Resolution{Multiplier: key.Multiplier, Timespan: key.Timespan}.Duration()
+}
+
+func timespanDuration(timespan domain.Timespan) time.Duration {
+	switch timespan {
+	case domain.TimespanMinute:
+		return time.Minute
+	case domain.TimespanHour:
+		return time.Hour
+	case domain.TimespanWeek:
+		return 7 * 24 * time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
+
+// MarketDataProvider is a source of historical OHLCV aggregates.
+// Implementations must return bars in ascending time order.
+type MarketDataProvider interface {
+	LoadAggregates(ctx context.Context, query AggregatesQuery) ([]domain.PriceData, error)
+}
+
+// NewMarketDataProvider builds the provider selected in the market config.
+func NewMarketDataProvider(cfg config.MarketConfig, polygonAPIKey string) (MarketDataProvider, error) {
+	switch cfg.Provider {
+	case "", MarketProviderPolygon:
+		if polygonAPIKey == "" {
+			return nil, fmt.Errorf("polygon market provider requires an API key")
+		}
+		return NewPolygonProvider(polygonAPIKey), nil
+	case MarketProviderFile:
+		if cfg.DataDir == "" {
+			return nil, fmt.Errorf("file market provider requires a data directory")
+		}
+		return NewFileProvider(cfg.DataDir), nil
+	default:
+		return nil, fmt.Errorf("unknown market provider %q", cfg.Provider)
+	}
+}
@@ -2,62 +2,215 @@ package service
 
 import (
 	"context"
+	"log"
+	"sort"
 	"time"
+	"tradeoff/backend/internal/config"
 	"tradeoff/backend/internal/domain"
+)
 
-	polygon "github.com/polygon-io/client-go/rest"
-	"github.com/polygon-io/client-go/rest/models"
+const (
+	DefaultBreakerFailureThreshold = 5
+	DefaultBreakerCooldown         = time.Minute
 )
 
 type MarketService struct {
-	hub           *Hub
-	polygonClient *polygon.Client
+	hub      *Hub
+	provider MarketDataProvider
+	cache    AggregateRepository
+	fallback MarketDataProvider
+	retry    retryPolicy
+	breaker  *circuitBreaker
 }
 
-func NewMarketService(hub *Hub, apiKey string) *MarketService {
-	polygonClient := polygon.New(apiKey)
+// NewMarketService creates a market service backed by provider. cache may be nil,
+// in which case every request goes straight to the provider.
+func NewMarketService(hub *Hub, provider MarketDataProvider, cache AggregateRepository, cfg config.MarketConfig) *MarketService {
+	failureThreshold := cfg.Breaker.FailureThreshold
+	if failureThreshold <= 0 {
+		failureThreshold = DefaultBreakerFailureThreshold
+	}
+	cooldown := cfg.Breaker.Cooldown
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+
+	var fallback MarketDataProvider
+	if cfg.FallbackDir != "" {
+		fallback = NewFileProvider(cfg.FallbackDir)
+	}
+
 	return &MarketService{
-		hub:           hub,
-		polygonClient: polygonClient,
+		hub:      hub,
+		provider: provider,
+		cache:    cache,
+		fallback: fallback,
+		retry:    newRetryPolicy(cfg.Retry),
+		breaker:  newCircuitBreaker(failureThreshold, cooldown),
+	}
+}
+
+// LoadPriceData returns the bars for query. With a cache configured, the parts of
+// the window that were fetched before are served from it and only the missing
+// edges are requested from the provider. If the provider stays unavailable, it
+// falls back to whatever the cache holds for the window and then to the
+// curated fallback series.
+func (m *MarketService) LoadPriceData(ctx context.Context, query AggregatesQuery) ([]domain.PriceData, error) {
+	priceData, err := m.loadPriceData(ctx, query)
+	if err == nil || ctx.Err() != nil {
+		return priceData, err
+	}
+	log.Printf("Error loading %s bars for %s: %v", query.Timespan, query.Ticker, err)
+
+	if m.cache != nil {
+		cached, cacheErr := m.cache.FindAggregates(query.Key(), query.From, query.To)
+		if cacheErr == nil && len(cached) > 0 {
+			log.Printf("Serving %d cached %s bars for %s while the provider is unavailable", len(cached), query.Timespan, query.Ticker)
+			return cached, nil
+		}
+	}
+
+	if m.fallback != nil {
+		curated, fallbackErr := m.fallback.LoadAggregates(ctx, query)
+		if fallbackErr == nil && len(curated) > 0 {
+			log.Printf("Serving %d curated %s bars for %s while the provider is unavailable", len(curated), query.Timespan, query.Ticker)
+			return curated, nil
+		}
 	}
+
+	return nil, err
 }
 
-func polygonPriceDataToDomain(p models.Agg) domain.PriceData {
-	return domain.PriceData{
-		Time:   time.Time(p.Timestamp).Unix(),
-		Open:   p.Open,
-		High:   p.High,
-		Low:    p.Low,
-		Close:  p.Close,
-		Volume: p.Volume,
+// fetch calls the provider through the circuit breaker, retrying failures
+// with exponential backoff and jitter.
+func (m *MarketService) fetch(ctx context.Context, query AggregatesQuery) ([]domain.PriceData, error) {
+	var lastErr error
+	for attempt := 0; attempt < m.retry.maxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, m.retry.backoff(attempt)); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := m.breaker.Allow(); err != nil {
+			return nil, err
+		}
+
+		priceData, err := m.provider.LoadAggregates(ctx, query)
+		if err == nil {
+			m.breaker.Success()
+			return priceData, nil
+		}
+		if ctx.Err() != nil {
+			m.breaker.Abort()
+			return nil, ctx.Err()
+		}
+
+		m.breaker.Failure()
+		lastErr = err
+		log.Printf("Attempt %d/%d loading %s bars for %s failed: %v", attempt+1, m.retry.maxAttempts, query.Timespan, query.Ticker, err)
 	}
+	return nil, lastErr
 }
 
-func (m *MarketService) LoadPriceData(ctx context.Context, ticker string, from time.Time, to time.Time, timespan models.Timespan, limit *int) ([]domain.PriceData, error) {
-	priceData := []domain.PriceData{}
-	params := models.ListAggsParams{
-		Ticker:     ticker,
-		Multiplier: 1,
-		Timespan:   timespan,
-		From:       models.Millis(from),
-		To:         models.Millis(to),
-	}.
-		WithAdjusted(true).
-		WithOrder(models.Order("asc"))
-	if limit != nil {
-		params = params.
-			WithLimit(*limit)
+func (m *MarketService) loadPriceData(ctx context.Context, query AggregatesQuery) ([]domain.PriceData, error) {
+	if m.cache == nil {
+		return m.fetch(ctx, query)
+	}
+
+	key := query.Key()
+	covered, err := m.cache.FindAggregateRanges(key)
+	if err != nil {
+		log.Printf("Error reading aggregate cache ranges for %s: %v", key.Ticker, err)
+		return m.fetch(ctx, query)
+	}
+
+	// Bars that are still forming must not be cached as final, so coverage
+	// never extends past the start of the current bar.
+	cutoff := time.Now().UTC().Truncate(query.BarDuration())
+
+	cacheTo := query.To
+	if !cacheTo.Before(cutoff) {
+		cacheTo = cutoff.Add(-time.Second)
 	}
 
-	iter := m.polygonClient.ListAggs(ctx, params)
+	for _, gap := range missingRanges(query.From, cacheTo, covered) {
+		// Coverage is recorded up to the last bar fetched, so the gap must be
+		// fetched in full; the limit is applied to what is read back from the
+		// cache.
+		gapQuery := query
+		gapQuery.From = gap.From
+		gapQuery.To = gap.To
+		gapQuery.Limit = nil
+
+		bars, err := m.fetch(ctx, gapQuery)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Fetched %d %s bars for %s from %s to %s", len(bars), key.Timespan, key.Ticker, gap.From, gap.To)
+
+		// The provider may not have the whole gap yet, so only the span up to
+		// the last bar it returned counts as covered; an empty response
+		// covers nothing and is fetched again next time.
+		if len(bars) == 0 {
+			continue
+		}
+		coverage := domain.TimeRange{From: gap.From, To: time.Unix(bars[len(bars)-1].Time, 0).UTC()}
+		if err := m.cache.SaveAggregates(key, coverage, bars); err != nil {
+			log.Printf("Error writing aggregate cache for %s: %v", key.Ticker, err)
+			return m.fetch(ctx, query)
+		}
+	}
 
-	for iter.Next() {
-		agg := iter.Item()
-		priceData = append(priceData, polygonPriceDataToDomain(agg))
+	priceData, err := m.cache.FindAggregates(key, query.From, query.To)
+	if err != nil {
+		log.Printf("Error reading aggregate cache for %s: %v", key.Ticker, err)
+		return m.fetch(ctx, query)
 	}
-	if iter.Err() != nil {
-		return nil, iter.Err()
+
+	// Bars newer than the cutoff are never cached; fetch them fresh.
+	if !query.To.Before(cutoff) {
+		tailQuery := query
+		if query.From.Before(cutoff) {
+			tailQuery.From = cutoff
+		}
+		tail, err := m.fetch(ctx, tailQuery)
+		if err != nil {
+			return nil, err
+		}
+		priceData = append(priceData, tail...)
 	}
 
+	if query.Limit != nil && len(priceData) > *query.Limit {
+		priceData = priceData[:*query.Limit]
+	}
 	return priceData, nil
 }
+
+// missingRanges returns the parts of [from, to] not contained in covered.
+func missingRanges(from time.Time, to time.Time, covered []domain.TimeRange) []domain.TimeRange {
+	sorted := make([]domain.TimeRange, len(covered))
+	copy(sorted, covered)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].From.Before(sorted[j].From)
+	})
+
+	missing := []domain.TimeRange{}
+	cursor := from
+	for _, r := range sorted {
+		if !r.To.After(cursor) {
+			continue
+		}
+		if !r.From.Before(to) {
+			break
+		}
+		if r.From.After(cursor) {
+			missing = append(missing, domain.TimeRange{From: cursor, To: r.From})
+		}
+		cursor = r.To
+	}
+	if to.After(cursor) {
+		missing = append(missing, domain.TimeRange{From: cursor, To: to})
+	}
+	return missing
+}
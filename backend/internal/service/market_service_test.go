@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+func TestMissingRanges(t *testing.T) {
+	at := func(day int) time.Time {
+		return time.Date(2024, time.January, day, 0, 0, 0, 0, time.UTC)
+	}
+	span := func(from, to int) domain.TimeRange {
+		return domain.TimeRange{From: at(from), To: at(to)}
+	}
+
+	tests := []struct {
+		name    string
+		from    int
+		to      int
+		covered []domain.TimeRange
+		want    []domain.TimeRange
+	}{
+		{
+			name: "nothing cached",
+			from: 1, to: 10,
+			want: []domain.TimeRange{span(1, 10)},
+		},
+		{
+			name: "fully covered",
+			from: 3, to: 8,
+			covered: []domain.TimeRange{span(1, 10)},
+			want:    []domain.TimeRange{},
+		},
+		{
+			name: "missing both edges",
+			from: 1, to: 10,
+			covered: []domain.TimeRange{span(3, 7)},
+			want:    []domain.TimeRange{span(1, 3), span(7, 10)},
+		},
+		{
+			name: "hole between unsorted ranges",
+			from: 1, to: 10,
+			covered: []domain.TimeRange{span(6, 10), span(1, 4)},
+			want:    []domain.TimeRange{span(4, 6)},
+		},
+		{
+			name: "overlapping ranges",
+			from: 1, to: 10,
+			covered: []domain.TimeRange{span(1, 5), span(2, 4), span(3, 8)},
+			want:    []domain.TimeRange{span(8, 10)},
+		},
+		{
+			name: "ranges outside the window",
+			from: 5, to: 8,
+			covered: []domain.TimeRange{span(1, 3), span(9, 12)},
+			want:    []domain.TimeRange{span(5, 8)},
+		},
+		{
+			name: "ranges touching the window",
+			from: 5, to: 8,
+			covered: []domain.TimeRange{span(1, 5), span(8, 12)},
+			want:    []domain.TimeRange{span(5, 8)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := missingRanges(at(tt.from), at(tt.to), tt.covered)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("missingRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// hourlyProvider serves an hourly bar at every hour of the query up to until,
+// if set, honouring its limit, and records the queries it was sent.
+type hourlyProvider struct {
+	queries []AggregatesQuery
+	until   time.Time
+}
+
+func (p *hourlyProvider) LoadAggregates(_ context.Context, query AggregatesQuery) ([]domain.PriceData, error) {
+	p.queries = append(p.queries, query)
+	var bars []domain.PriceData
+	for t := query.From; !t.After(query.To); t = t.Add(time.Hour) {
+		if query.Limit != nil && len(bars) == *query.Limit || !p.until.IsZero() && t.After(p.until) {
+			break
+		}
+		bars = append(bars, domain.PriceData{Time: t.Unix(), Open: 1, High: 1, Low: 1, Close: 1})
+	}
+	return bars, nil
+}
+
+// memoryAggregates is an in-memory aggregate cache for a single series that,
+// like the Postgres store, keeps one bar per time.
+type memoryAggregates struct {
+	bars    []domain.PriceData
+	covered []domain.TimeRange
+}
+
+func (c *memoryAggregates) FindAggregates(_ domain.AggregateKey, from time.Time, to time.Time) ([]domain.PriceData, error) {
+	var bars []domain.PriceData
+	for _, bar := range c.bars {
+		if bar.Time >= from.Unix() && bar.Time <= to.Unix() {
+			bars = append(bars, bar)
+		}
+	}
+	return bars, nil
+}
+
+func (c *memoryAggregates) FindAggregateRanges(domain.AggregateKey) ([]domain.TimeRange, error) {
+	return c.covered, nil
+}
+
+func (c *memoryAggregates) SaveAggregates(_ domain.AggregateKey, coverage domain.TimeRange, bars []domain.PriceData) error {
+	for _, bar := range bars {
+		if !slices.ContainsFunc(c.bars, func(cached domain.PriceData) bool { return cached.Time == bar.Time }) {
+			c.bars = append(c.bars, bar)
+		}
+	}
+	c.covered = append(c.covered, coverage)
+	return nil
+}
+
+func TestLoadPriceDataCachesWholeGapForLimitedQuery(t *testing.T) {
+	provider := &hourlyProvider{}
+	cache := &memoryAggregates{}
+	market := NewMarketService(nil, provider, cache, config.MarketConfig{})
+
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	query := ResolutionHour.query("X:BTCUSD", from, from.Add(47*time.Hour))
+	limit := 5
+	query.Limit = &limit
+
+	bars, err := market.loadPriceData(context.Background(), query)
+	if err != nil {
+		t.Fatalf("loadPriceData: %v", err)
+	}
+	if len(bars) != limit {
+		t.Errorf("got %d bars, want the limit of %d", len(bars), limit)
+	}
+	if len(provider.queries) != 1 || provider.queries[0].Limit != nil {
+		t.Fatalf("gap was fetched with %+v, want one query without a limit", provider.queries)
+	}
+	if len(cache.bars) != 48 {
+		t.Fatalf("cached %d bars, want the whole 48-hour gap", len(cache.bars))
+	}
+
+	query.Limit = nil
+	bars, err = market.loadPriceData(context.Background(), query)
+	if err != nil {
+		t.Fatalf("loadPriceData: %v", err)
+	}
+	if len(bars) != 48 || len(provider.queries) != 1 {
+		t.Fatalf("got %d bars with %d provider calls, want 48 bars from the cache alone", len(bars), len(provider.queries))
+	}
+}
+
+func TestLoadPriceDataCoversOnlyFetchedBars(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(23 * time.Hour)
+
+	tests := []struct {
+		name        string
+		until       time.Time
+		wantBars    int
+		wantCovered []domain.TimeRange
+	}{
+		{
+			name:        "empty response covers nothing",
+			until:       from.Add(-time.Hour),
+			wantCovered: nil,
+		},
+		{
+			name:        "partial response covers up to its last bar",
+			until:       from.Add(9 * time.Hour),
+			wantBars:    10,
+			wantCovered: []domain.TimeRange{{From: from, To: from.Add(9 * time.Hour)}},
+		},
+		{
+			name:        "full response covers the gap",
+			wantBars:    24,
+			wantCovered: []domain.TimeRange{{From: from, To: to}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &hourlyProvider{until: tt.until}
+			cache := &memoryAggregates{}
+			market := NewMarketService(nil, provider, cache, config.MarketConfig{})
+			query := ResolutionHour.query("X:BTCUSD", from, to)
+
+			bars, err := market.loadPriceData(context.Background(), query)
+			if err != nil {
+				t.Fatalf("loadPriceData: %v", err)
+			}
+			if len(bars) != tt.wantBars {
+				t.Errorf("got %d bars, want %d", len(bars), tt.wantBars)
+			}
+			if !reflect.DeepEqual(cache.covered, tt.wantCovered) {
+				t.Fatalf("covered = %v, want %v", cache.covered, tt.wantCovered)
+			}
+
+			// Once the provider catches up, the uncovered rest is fetched.
+			provider.until = time.Time{}
+			if bars, err = market.loadPriceData(context.Background(), query); err != nil {
+				t.Fatalf("loadPriceData: %v", err)
+			}
+			if len(bars) != 24 {
+				t.Fatalf("got %d bars after the provider caught up, want 24", len(bars))
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"tradeoff/backend/internal/domain"
+)
+
+// MaxPendingOrders is how many entry orders a player may have resting at once.
+const MaxPendingOrders = 10
+
+// OrderRequest describes a new entry order.
+type OrderRequest struct {
+	Type  domain.OrderType
+	Side  domain.PositionType
+	Price float64
+	Size  PositionSize
+	Exits PositionExits
+}
+
+// OrderUpdate reports an order leaving the book, with the position it opened
+// if it was filled.
+type OrderUpdate struct {
+	PlayerID string
+	Order    domain.Order
+	Position *domain.Position
+}
+
+// OrderService holds the resting entry orders of the current round and fills
+// them against live bars. Pending orders reserve their margin through
+// PlayerService, which releases it again if they are cancelled or expire.
+type OrderService struct {
+	playerService *PlayerService
+	orders        map[string][]*domain.Order
+	mu            sync.Mutex
+}
+
+func NewOrderService(playerService *PlayerService) *OrderService {
+	return &OrderService{
+		playerService: playerService,
+		orders:        make(map[string][]*domain.Order),
+	}
+}
+
+// validateOrderPrice checks that a limit order rests on the favourable side of
+// the market and a stop order beyond it, so neither fills straight away.
+func validateOrderPrice(req OrderRequest, currentPrice float64) error {
+	if req.Price <= 0 {
+		return fmt.Errorf("%w: price must be positive", ErrInvalidOrder)
+	}
+
+	below := req.Price < currentPrice
+	above := req.Price > currentPrice
+	switch {
+	case req.Type == domain.OrderTypeLimit && req.Side == domain.PositionTypeLong && !below,
+		req.Type == domain.OrderTypeStop && req.Side == domain.PositionTypeShort && !below:
+		return fmt.Errorf("%w: %s %s order must be below %.2f", ErrInvalidOrder, req.Type, req.Side, currentPrice)
+	case req.Type == domain.OrderTypeLimit && req.Side == domain.PositionTypeShort && !above,
+		req.Type == domain.OrderTypeStop && req.Side == domain.PositionTypeLong && !above:
+		return fmt.Errorf("%w: %s %s order must be above %.2f", ErrInvalidOrder, req.Type, req.Side, currentPrice)
+	}
+	return nil
+}
+
+// PlaceOrder validates an entry order, reserves its margin and adds it to the
+// book.
+func (s *OrderService) PlaceOrder(playerID string, req OrderRequest, currentPrice float64, maxLeverage float64) (*domain.Order, error) {
+	if req.Type != domain.OrderTypeLimit && req.Type != domain.OrderTypeStop {
+		return nil, fmt.Errorf("%w: unknown order type %q", ErrInvalidOrder, req.Type)
+	}
+	if req.Side != domain.PositionTypeLong && req.Side != domain.PositionTypeShort {
+		return nil, fmt.Errorf("%w: unknown side %q", ErrInvalidOrder, req.Side)
+	}
+	if err := validateOrderPrice(req, currentPrice); err != nil {
+		return nil, err
+	}
+	leverage, err := req.Size.leverage(maxLeverage)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Exits.validate(req.Side, req.Price); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.orders[playerID]) >= MaxPendingOrders {
+		return nil, fmt.Errorf("%w: at most %d pending orders", ErrInvalidOrder, MaxPendingOrders)
+	}
+
+	margin, err := s.playerService.ReserveBalance(playerID, req.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &domain.Order{
+		ID:            generateUUID(),
+		Type:          req.Type,
+		Side:          req.Side,
+		Price:         req.Price,
+		Margin:        margin,
+		Leverage:      leverage,
+		StopLoss:      req.Exits.StopLoss,
+		TakeProfit:    req.Exits.TakeProfit,
+		TrailDistance: req.Exits.TrailDistance,
+		TrailPercent:  req.Exits.TrailPercent,
+		Status:        domain.OrderStatusPending,
+		CreatedAt:     time.Now(),
+	}
+	s.orders[playerID] = append(s.orders[playerID], order)
+
+	placed := *order
+	return &placed, nil
+}
+
+// CancelOrder removes a pending order and releases its margin.
+func (s *OrderService) CancelOrder(playerID string, orderID string) (*domain.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := s.orders[playerID]
+	for i, order := range orders {
+		if order.ID != orderID {
+			continue
+		}
+		s.orders[playerID] = append(orders[:i:i], orders[i+1:]...)
+		s.playerService.ReleaseBalance(playerID, order.Margin)
+
+		order.Status = domain.OrderStatusCancelled
+		cancelled := *order
+		return &cancelled, nil
+	}
+	return nil, ErrOrderNotFound
+}
+
+// GetPendingOrders returns a copy of the player's pending orders.
+func (s *OrderService) GetPendingOrders(playerID string) []domain.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := make([]domain.Order, 0, len(s.orders[playerID]))
+	for _, order := range s.orders[playerID] {
+		pending = append(pending, *order)
+	}
+	return pending
+}
+
+// EvaluateOrders fills the pending orders whose price lies within the bar's
+// range, opening each position at the order price. Triggered orders of a
+// player already at MaxActivePositions stay pending.
+func (s *OrderService) EvaluateOrders(bar domain.PriceData) []OrderUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var updates []OrderUpdate
+	for playerID, orders := range s.orders {
+		remaining := orders[:0]
+		for _, order := range orders {
+			if !orderTriggered(order, bar) {
+				remaining = append(remaining, order)
+				continue
+			}
+
+			position, err := s.playerService.FillOrder(playerID, order)
+			if err != nil {
+				remaining = append(remaining, order)
+				continue
+			}
+			order.Status = domain.OrderStatusFilled
+			updates = append(updates, OrderUpdate{
+				PlayerID: playerID,
+				Order:    *order,
+				Position: position,
+			})
+		}
+		s.orders[playerID] = remaining
+	}
+	return updates
+}
+
+// orderTriggered reports whether the bar reached the order's price.
+func orderTriggered(order *domain.Order, bar domain.PriceData) bool {
+	buysBelow := (order.Type == domain.OrderTypeLimit) == (order.Side == domain.PositionTypeLong)
+	if buysBelow {
+		return bar.Low <= order.Price
+	}
+	return bar.High >= order.Price
+}
+
+// ExpireAll empties the book at the end of the Live phase and releases the
+// margin of every pending order.
+func (s *OrderService) ExpireAll() []OrderUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var updates []OrderUpdate
+	for playerID, orders := range s.orders {
+		for _, order := range orders {
+			s.playerService.ReleaseBalance(playerID, order.Margin)
+			order.Status = domain.OrderStatusExpired
+			updates = append(updates, OrderUpdate{
+				PlayerID: playerID,
+				Order:    *order,
+			})
+		}
+	}
+	s.orders = make(map[string][]*domain.Order)
+	return updates
+}
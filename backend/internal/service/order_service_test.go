@@ -0,0 +1,249 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"tradeoff/backend/internal/domain"
+)
+
+func TestValidateOrderPrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		orderType domain.OrderType
+		side      domain.PositionType
+		price     float64
+		wantErr   error
+	}{
+		{name: "limit buy below the market", orderType: domain.OrderTypeLimit, side: domain.PositionTypeLong, price: 95},
+		{name: "limit buy above the market", orderType: domain.OrderTypeLimit, side: domain.PositionTypeLong, price: 105, wantErr: ErrInvalidOrder},
+		{name: "limit sell above the market", orderType: domain.OrderTypeLimit, side: domain.PositionTypeShort, price: 105},
+		{name: "limit sell at the market", orderType: domain.OrderTypeLimit, side: domain.PositionTypeShort, price: 100, wantErr: ErrInvalidOrder},
+		{name: "stop buy above the market", orderType: domain.OrderTypeStop, side: domain.PositionTypeLong, price: 105},
+		{name: "stop buy below the market", orderType: domain.OrderTypeStop, side: domain.PositionTypeLong, price: 95, wantErr: ErrInvalidOrder},
+		{name: "stop sell below the market", orderType: domain.OrderTypeStop, side: domain.PositionTypeShort, price: 95},
+		{name: "stop sell above the market", orderType: domain.OrderTypeStop, side: domain.PositionTypeShort, price: 105, wantErr: ErrInvalidOrder},
+		{name: "non-positive price", orderType: domain.OrderTypeStop, side: domain.PositionTypeShort, price: 0, wantErr: ErrInvalidOrder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := OrderRequest{Type: tt.orderType, Side: tt.side, Price: tt.price}
+			if err := validateOrderPrice(req, 100); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("validateOrderPrice() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlaceOrderReservesMargin(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         OrderRequest
+		wantMargin  float64
+		wantBalance float64
+		wantErr     error
+	}{
+		{
+			name:        "fraction of the balance",
+			req:         OrderRequest{Type: domain.OrderTypeLimit, Side: domain.PositionTypeLong, Price: 90, Size: PositionSize{Fraction: 0.25, Leverage: 4}},
+			wantMargin:  25,
+			wantBalance: 75,
+		},
+		{
+			name:        "unknown order type",
+			req:         OrderRequest{Type: "market", Side: domain.PositionTypeLong, Price: 90},
+			wantBalance: 100,
+			wantErr:     ErrInvalidOrder,
+		},
+		{
+			name:        "leverage above the cap",
+			req:         OrderRequest{Type: domain.OrderTypeStop, Side: domain.PositionTypeLong, Price: 110, Size: PositionSize{Leverage: 20}},
+			wantBalance: 100,
+			wantErr:     ErrInvalidLeverage,
+		},
+		{
+			name:        "more than the balance",
+			req:         OrderRequest{Type: domain.OrderTypeStop, Side: domain.PositionTypeShort, Price: 90, Size: PositionSize{Amount: 150}},
+			wantBalance: 100,
+			wantErr:     ErrInsufficientFunds,
+		},
+		{
+			name:        "exit level on the wrong side of the order price",
+			req:         OrderRequest{Type: domain.OrderTypeLimit, Side: domain.PositionTypeLong, Price: 90, Exits: PositionExits{StopLoss: 95}},
+			wantBalance: 100,
+			wantErr:     ErrInvalidExitLevel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players := newTestPlayerService("alice")
+			orders := NewOrderService(players)
+			session := players.GetPlayerSessionOrCreate("alice", nil)
+
+			order, err := orders.PlaceOrder("alice", tt.req, 100, DefaultMaxLeverage)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PlaceOrder error = %v, want %v", err, tt.wantErr)
+			}
+			if session.Balance != tt.wantBalance || session.Reserved != tt.wantMargin {
+				t.Errorf("balance %v with %v reserved, want %v with %v", session.Balance, session.Reserved, tt.wantBalance, tt.wantMargin)
+			}
+			if err != nil {
+				if pending := orders.GetPendingOrders("alice"); len(pending) != 0 {
+					t.Fatalf("a rejected order left %d orders pending", len(pending))
+				}
+				return
+			}
+			if order.Margin != tt.wantMargin || order.Status != domain.OrderStatusPending {
+				t.Fatalf("order = %+v, want a pending order holding %v", order, tt.wantMargin)
+			}
+		})
+	}
+}
+
+func TestPlaceOrderLimitsPendingOrders(t *testing.T) {
+	players := newTestPlayerService("alice")
+	orders := NewOrderService(players)
+	req := OrderRequest{Type: domain.OrderTypeLimit, Side: domain.PositionTypeLong, Price: 90, Size: PositionSize{Amount: 5}}
+
+	for i := range MaxPendingOrders {
+		if _, err := orders.PlaceOrder("alice", req, 100, DefaultMaxLeverage); err != nil {
+			t.Fatalf("order %d: %v", i, err)
+		}
+	}
+	if _, err := orders.PlaceOrder("alice", req, 100, DefaultMaxLeverage); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("order past the limit: error = %v, want %v", err, ErrInvalidOrder)
+	}
+	if balance := players.GetPlayerSessionOrCreate("alice", nil).Balance; balance != 50 {
+		t.Fatalf("balance = %v, want 50 held by %d orders", balance, MaxPendingOrders)
+	}
+}
+
+func TestOrdersReleaseMargin(t *testing.T) {
+	tests := []struct {
+		name       string
+		leave      func(orders *OrderService, order *domain.Order) []OrderUpdate
+		wantStatus domain.OrderStatus
+	}{
+		{
+			name: "cancelled",
+			leave: func(orders *OrderService, order *domain.Order) []OrderUpdate {
+				cancelled, err := orders.CancelOrder("alice", order.ID)
+				if err != nil {
+					return nil
+				}
+				return []OrderUpdate{{PlayerID: "alice", Order: *cancelled}}
+			},
+			wantStatus: domain.OrderStatusCancelled,
+		},
+		{
+			name: "expired",
+			leave: func(orders *OrderService, _ *domain.Order) []OrderUpdate {
+				return orders.ExpireAll()
+			},
+			wantStatus: domain.OrderStatusExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players := newTestPlayerService("alice")
+			orders := NewOrderService(players)
+			session := players.GetPlayerSessionOrCreate("alice", nil)
+			req := OrderRequest{Type: domain.OrderTypeStop, Side: domain.PositionTypeLong, Price: 110, Size: PositionSize{Amount: 40}}
+			order, err := orders.PlaceOrder("alice", req, 100, DefaultMaxLeverage)
+			if err != nil {
+				t.Fatalf("PlaceOrder: %v", err)
+			}
+
+			updates := tt.leave(orders, order)
+
+			if len(updates) != 1 || updates[0].Order.Status != tt.wantStatus || updates[0].Position != nil {
+				t.Fatalf("updates = %+v, want the order %s", updates, tt.wantStatus)
+			}
+			if session.Balance != StartingBalance || session.Reserved != 0 || session.Trades != 0 {
+				t.Errorf("balance %v with %v reserved after %d trades, want the margin and the trade given back", session.Balance, session.Reserved, session.Trades)
+			}
+			if pending := orders.GetPendingOrders("alice"); len(pending) != 0 {
+				t.Errorf("%d orders still pending", len(pending))
+			}
+		})
+	}
+}
+
+func TestCancelOrderNotFound(t *testing.T) {
+	orders := NewOrderService(newTestPlayerService("alice"))
+	if _, err := orders.CancelOrder("alice", "missing"); !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("CancelOrder error = %v, want %v", err, ErrOrderNotFound)
+	}
+}
+
+func TestEvaluateOrdersFills(t *testing.T) {
+	tests := []struct {
+		name      string
+		orderType domain.OrderType
+		side      domain.PositionType
+		price     float64
+		bar       domain.PriceData
+		wantFill  bool
+	}{
+		{name: "limit buy reached", orderType: domain.OrderTypeLimit, side: domain.PositionTypeLong, price: 95, bar: domain.PriceData{High: 99, Low: 94}, wantFill: true},
+		{name: "limit buy not reached", orderType: domain.OrderTypeLimit, side: domain.PositionTypeLong, price: 95, bar: domain.PriceData{High: 99, Low: 96}},
+		{name: "limit sell reached", orderType: domain.OrderTypeLimit, side: domain.PositionTypeShort, price: 105, bar: domain.PriceData{High: 105, Low: 101}, wantFill: true},
+		{name: "stop buy reached", orderType: domain.OrderTypeStop, side: domain.PositionTypeLong, price: 105, bar: domain.PriceData{High: 106, Low: 101}, wantFill: true},
+		{name: "stop buy not reached", orderType: domain.OrderTypeStop, side: domain.PositionTypeLong, price: 105, bar: domain.PriceData{High: 104, Low: 90}},
+		{name: "stop sell reached", orderType: domain.OrderTypeStop, side: domain.PositionTypeShort, price: 95, bar: domain.PriceData{High: 99, Low: 95}, wantFill: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players := newTestPlayerService("alice")
+			orders := NewOrderService(players)
+			session := players.GetPlayerSessionOrCreate("alice", nil)
+			req := OrderRequest{Type: tt.orderType, Side: tt.side, Price: tt.price, Size: PositionSize{Amount: 20, Leverage: 2}}
+			if _, err := orders.PlaceOrder("alice", req, 100, DefaultMaxLeverage); err != nil {
+				t.Fatalf("PlaceOrder: %v", err)
+			}
+
+			updates := orders.EvaluateOrders(tt.bar)
+
+			if !tt.wantFill {
+				if len(updates) != 0 || len(orders.GetPendingOrders("alice")) != 1 || session.Reserved != 20 {
+					t.Fatalf("got %d updates and %v reserved, want the order left pending", len(updates), session.Reserved)
+				}
+				return
+			}
+			if len(updates) != 1 || updates[0].Order.Status != domain.OrderStatusFilled || updates[0].Position == nil {
+				t.Fatalf("updates = %+v, want one filled order with its position", updates)
+			}
+			position := updates[0].Position
+			if position.Type != tt.side || position.EntryPrice != tt.price || position.Margin != 20 || position.Leverage != 2 {
+				t.Errorf("position = %+v, want a 2x %s of 20 at %v", position, tt.side, tt.price)
+			}
+			if session.Balance != 80 || session.Reserved != 0 || len(orders.GetPendingOrders("alice")) != 0 {
+				t.Errorf("balance %v with %v reserved, want 80 with the margin moved into the position", session.Balance, session.Reserved)
+			}
+		})
+	}
+}
+
+func TestEvaluateOrdersKeepsOrdersOfFullPlayers(t *testing.T) {
+	players := newTestPlayerService("alice")
+	orders := NewOrderService(players)
+	req := OrderRequest{Type: domain.OrderTypeLimit, Side: domain.PositionTypeLong, Price: 95, Size: PositionSize{Amount: 5}}
+	if _, err := orders.PlaceOrder("alice", req, 100, DefaultMaxLeverage); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	for i := range MaxActivePositions {
+		if _, err := players.CreatePosition("alice", domain.PositionTypeLong, 100, PositionSize{Amount: 5}, PositionExits{}, 1); err != nil {
+			t.Fatalf("position %d: %v", i, err)
+		}
+	}
+
+	if updates := orders.EvaluateOrders(domain.PriceData{High: 96, Low: 90}); len(updates) != 0 {
+		t.Fatalf("got %d fills for a player at %d positions, want none", len(updates), MaxActivePositions)
+	}
+	if pending := orders.GetPendingOrders("alice"); len(pending) != 1 || pending[0].Status != domain.OrderStatusPending {
+		t.Fatalf("pending = %+v, want the order still pending", pending)
+	}
+}
@@ -1,22 +1,108 @@
 package service
 
 import (
-	"errors"
+	"fmt"
+	"math"
+	"slices"
 	"sort"
 	"sync"
 	"time"
+	"tradeoff/backend/internal/config"
 	"tradeoff/backend/internal/domain"
 )
 
+// MinPositionNotional is the smallest amount of balance a position may commit.
+const MinPositionNotional = 1.0
+
+// MaxActivePositions is how many positions a player may hold at once.
+const MaxActivePositions = 10
+
+const (
+	DefaultMaxLeverage       = 10.0
+	DefaultMaintenanceMargin = 0.005
+)
+
+// PositionSize is how much of a player's balance a new position commits. Set
+// either Amount, in balance units, or Fraction of the current balance; leaving
+// both zero commits the whole balance. Leverage multiplies the committed
+// margin into the position's exposure; zero means 1x.
+type PositionSize struct {
+	Amount   float64
+	Fraction float64
+	Leverage float64
+}
+
+// leverage validates the requested leverage against maxLeverage.
+func (size PositionSize) leverage(maxLeverage float64) (float64, error) {
+	if size.Leverage == 0 {
+		return 1, nil
+	}
+	if size.Leverage < 1 || size.Leverage > maxLeverage {
+		return 0, fmt.Errorf("%w: must be between 1 and %g", ErrInvalidLeverage, maxLeverage)
+	}
+	return size.Leverage, nil
+}
+
+// notional resolves the size against balance, checking that it is well formed,
+// meets MinPositionNotional and is covered by balance.
+func (size PositionSize) notional(balance float64) (float64, error) {
+	var notional float64
+	switch {
+	case size.Amount != 0 && size.Fraction != 0:
+		return 0, fmt.Errorf("%w: set either an amount or a fraction, not both", ErrInvalidPositionSize)
+	case size.Amount != 0:
+		if size.Amount < 0 {
+			return 0, fmt.Errorf("%w: amount must be positive", ErrInvalidPositionSize)
+		}
+		notional = size.Amount
+	case size.Fraction != 0:
+		if size.Fraction < 0 || size.Fraction > 1 {
+			return 0, fmt.Errorf("%w: fraction must be between 0 and 1", ErrInvalidPositionSize)
+		}
+		notional = balance * size.Fraction
+	default:
+		notional = balance
+	}
+
+	if notional < MinPositionNotional {
+		return 0, fmt.Errorf("%w of %.2f", ErrBelowMinNotional, MinPositionNotional)
+	}
+	if notional > balance {
+		return 0, fmt.Errorf("%w: position needs %.2f, balance is %.2f", ErrInsufficientFunds, notional, balance)
+	}
+	return notional, nil
+}
+
+// Liquidation is a position the liquidation engine force-closed.
+type Liquidation struct {
+	PlayerID string
+	Position domain.ClosedPosition
+}
+
 // PlayerService is the sole, concurrent-safe owner of all live player state for a round.
 type PlayerService struct {
-	playerSessions map[string]*domain.PlayerState
-	mu             sync.RWMutex
+	playerSessions    map[string]*domain.PlayerState
+	maintenanceMargin float64
+	costs             costModel
+	funding           config.FundingConfig
+	limits            config.LimitsConfig
+	trades            *TradeHistory
+	roundID           string
+	mu                sync.RWMutex
 }
 
-func NewPlayerService() *PlayerService {
+func NewPlayerService(config config.TradingConfig, trades *TradeHistory) *PlayerService {
+	maintenanceMargin := config.MaintenanceMargin
+	if maintenanceMargin <= 0 || maintenanceMargin >= 1 {
+		maintenanceMargin = DefaultMaintenanceMargin
+	}
 	return &PlayerService{
-		playerSessions: make(map[string]*domain.PlayerState),
+		playerSessions:    make(map[string]*domain.PlayerState),
+		maintenanceMargin: maintenanceMargin,
+		costs:             newCostModel(config.Costs),
+		funding:           config.Funding,
+		limits:            config.Limits,
+		trades:            trades,
 	}
 }
 
@@ -50,7 +136,7 @@ func (s *PlayerService) GetPlayerSessionOrCreate(playerID string, username *stri
 		Username: *username,
 		BasePlayerState: domain.BasePlayerState{
 			Balance:         StartingBalance,
-			ActivePosition:  nil,
+			ActivePositions: []*domain.Position{},
 			ClosedPositions: []domain.ClosedPosition{},
 		},
 	}
@@ -58,86 +144,392 @@ func (s *PlayerService) GetPlayerSessionOrCreate(playerID string, username *stri
 	return newSession
 }
 
-// CreatePosition now returns an error if an action is invalid.
-func (s *PlayerService) CreatePosition(playerID string, positionType domain.PositionType, entryPrice float64) (*domain.Position, error) {
+// CreatePosition opens a position committing size of the player's balance as
+// margin, at up to maxLeverage, with optional exit levels. It returns an error
+// if an action is invalid.
+func (s *PlayerService) CreatePosition(playerID string, positionType domain.PositionType, entryPrice float64, size PositionSize, exits PositionExits, maxLeverage float64) (*domain.Position, error) {
 	s.mu.Lock() // We need a full write lock since we are modifying the session.
 	defer s.mu.Unlock()
 
 	session, exists := s.playerSessions[playerID]
 	if !exists {
 		// This case should ideally not happen if GetPlayerSessionOrCreate is called on connect.
-		return nil, errors.New("player session not found")
+		return nil, ErrSessionNotFound
+	}
+
+	if len(session.ActivePositions) >= MaxActivePositions {
+		return nil, ErrTooManyPositions
 	}
 
-	if session.ActivePosition != nil {
-		return nil, errors.New("player already has an active position")
+	now := time.Now()
+	if err := s.checkOrderUnsafe(session, true, now); err != nil {
+		return nil, err
 	}
 
 	if session.Balance == 0 {
-		return nil, errors.New("player has no balance")
+		return nil, ErrNoBalance
+	}
+
+	margin, err := size.notional(session.Balance)
+	if err != nil {
+		return nil, err
+	}
+	leverage, err := size.leverage(maxLeverage)
+	if err != nil {
+		return nil, err
+	}
+	if err := exits.validate(positionType, entryPrice); err != nil {
+		return nil, err
 	}
 
-	quantity := session.Balance / entryPrice
+	s.recordOrderUnsafe(session, true, now)
+	session.Balance -= margin
+	return s.openPositionUnsafe(session, positionType, entryPrice, executionMarket, margin, leverage, exits), nil
+}
+
+// openPositionUnsafe adds a new position to the session's active positions,
+// filled at price through the cost model. The margin must already have been
+// taken from the balance. Must be called with s.mu held.
+func (s *PlayerService) openPositionUnsafe(session *domain.PlayerState, positionType domain.PositionType, price float64, kind executionKind, margin float64, leverage float64, exits PositionExits) *domain.Position {
+	notional := margin * leverage
+	buy := positionType == domain.PositionTypeLong
+	entryPrice, costs := s.costs.fill(price, buy, notional, kind, s.crowdImbalanceUnsafe(buy))
 
 	position := &domain.Position{
-		Type:       positionType,
-		EntryPrice: entryPrice,
-		EntryTime:  time.Now(),
-		Quantity:   quantity,
+		ID:           generateUUID(),
+		Type:         positionType,
+		EntryPrice:   entryPrice,
+		EntryTime:    time.Now(),
+		Quantity:     notional / entryPrice,
+		Leverage:     leverage,
+		Margin:       margin,
+		StopLoss:     exits.StopLoss,
+		TakeProfit:   exits.TakeProfit,
+		TrailingStop: exits.trailingStop(positionType, price),
+		Costs:        costs,
 	}
-	session.ActivePosition = position
-	session.Balance = 0
+	position.LiquidationPrice = s.liquidationPrice(position)
+	session.ActivePositions = append(session.ActivePositions, position)
+
+	s.trades.Record(domain.Trade{
+		ID:         generateUUID(),
+		PlayerID:   session.PlayerId,
+		RoundID:    s.roundID,
+		PositionID: position.ID,
+		Action:     domain.TradeActionOpen,
+		Type:       position.Type,
+		Price:      position.EntryPrice,
+		Quantity:   position.Quantity,
+		Leverage:   position.Leverage,
+		Margin:     position.Margin,
+		Fees:       costs.Fees,
+		Time:       position.EntryTime,
+	})
+	return position
+}
 
+// SetRound sets the round that trades are recorded against.
+func (s *PlayerService) SetRound(roundID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roundID = roundID
+}
+
+// crowdImbalanceUnsafe returns the share of open positions on the buying (or
+// selling) side minus the share on the other. Must be called with s.mu held.
+func (s *PlayerService) crowdImbalanceUnsafe(buy bool) float64 {
+	longPositions, shortPositions := s.positionsCountUnsafe()
+	total := longPositions + shortPositions
+	if total == 0 {
+		return 0
+	}
+	imbalance := float64(longPositions-shortPositions) / float64(total)
+	if !buy {
+		imbalance = -imbalance
+	}
+	return imbalance
+}
+
+// ReserveBalance takes size of the player's balance aside for a pending order
+// and returns the amount reserved.
+func (s *PlayerService) ReserveBalance(playerID string, size PositionSize) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.playerSessions[playerID]
+	if !exists {
+		return 0, ErrSessionNotFound
+	}
+
+	now := time.Now()
+	if err := s.checkOrderUnsafe(session, true, now); err != nil {
+		return 0, err
+	}
+
+	if session.Balance == 0 {
+		return 0, ErrNoBalance
+	}
+
+	amount, err := size.notional(session.Balance)
+	if err != nil {
+		return 0, err
+	}
+	s.recordOrderUnsafe(session, true, now)
+	session.Balance -= amount
+	session.Reserved += amount
+	return amount, nil
+}
+
+// ReleaseBalance returns an amount reserved by ReserveBalance to the balance,
+// along with the trade the order counted against the round's limit.
+func (s *PlayerService) ReleaseBalance(playerID string, amount float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, exists := s.playerSessions[playerID]; exists {
+		session.Balance += amount
+		session.Reserved = max(session.Reserved-amount, 0)
+		session.Trades = max(session.Trades-1, 0)
+	}
+}
+
+// FillOrder opens the position of a triggered entry order, using the margin
+// the order reserved. Limit orders fill at their price; stop orders become
+// market orders once triggered.
+func (s *PlayerService) FillOrder(playerID string, order *domain.Order) (*domain.Position, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.playerSessions[playerID]
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+
+	if len(session.ActivePositions) >= MaxActivePositions {
+		return nil, ErrTooManyPositions
+	}
+
+	exits := PositionExits{
+		StopLoss:      order.StopLoss,
+		TakeProfit:    order.TakeProfit,
+		TrailDistance: order.TrailDistance,
+		TrailPercent:  order.TrailPercent,
+	}
+	kind := executionMarket
+	if order.Type == domain.OrderTypeLimit {
+		kind = executionLimit
+	}
+	position := s.openPositionUnsafe(session, order.Side, order.Price, kind, order.Margin, order.Leverage, exits)
+	session.Reserved = max(session.Reserved-order.Margin, 0)
 	return position, nil
 }
 
-// ClosePosition now returns an error for invalid states.
-func (s *PlayerService) ClosePosition(playerID string, closePrice float64) (*domain.ClosedPosition, error) {
+// ClosePosition closes the player's position with the given ID. An empty ID
+// closes the player's only position. It returns an error for invalid states.
+func (s *PlayerService) ClosePosition(playerID string, positionID string, closePrice float64) (*domain.ClosedPosition, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	session, exists := s.playerSessions[playerID]
 	if !exists {
-		return nil, errors.New("player session not found")
+		return nil, ErrSessionNotFound
 	}
 
-	if session.ActivePosition == nil {
-		return nil, errors.New("no active position to close")
+	position, err := findPositionUnsafe(session, positionID)
+	if err != nil {
+		return nil, err
 	}
 
-	activePosition := session.ActivePosition
-	pnl, pnlPercentage := s.calculatePnl(activePosition, closePrice)
-	initialInvestment := activePosition.Quantity * activePosition.EntryPrice
+	now := time.Now()
+	if err := s.checkOrderUnsafe(session, false, now); err != nil {
+		return nil, err
+	}
+	if err := s.checkHoldUnsafe(position, now); err != nil {
+		return nil, err
+	}
+
+	s.recordOrderUnsafe(session, false, now)
+	closedPosition := s.closePositionUnsafe(session, position, closePrice, domain.CloseReasonManual)
+	return &closedPosition, nil
+}
+
+// ReversePosition closes the player's position with the given ID, or their
+// only position, and opens one in the opposite direction at the same price
+// under a single lock. The new position keeps the leverage and, balance
+// permitting, the margin of the old one; exit levels are not carried over.
+func (s *PlayerService) ReversePosition(playerID string, positionID string, price float64) (*domain.ClosedPosition, *domain.Position, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.playerSessions[playerID]
+	if !exists {
+		return nil, nil, ErrSessionNotFound
+	}
+
+	position, err := findPositionUnsafe(session, positionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if err := s.checkOrderUnsafe(session, true, now); err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkHoldUnsafe(position, now); err != nil {
+		return nil, nil, err
+	}
+
+	// Check the reopened position is viable before closing anything, so a
+	// failed reverse leaves the player's state untouched. The close is priced
+	// as it will be filled, exit costs included.
+	_, _, pnl := s.exitUnsafe(position, price, domain.CloseReasonReverse)
+	if min(position.Margin, session.Balance+position.Margin+pnl) < MinPositionNotional {
+		return nil, nil, fmt.Errorf("%w of %.2f", ErrBelowMinNotional, MinPositionNotional)
+	}
+
+	s.recordOrderUnsafe(session, true, now)
+	closedPosition := s.closePositionUnsafe(session, position, price, domain.CloseReasonReverse)
+
+	positionType := domain.PositionTypeShort
+	if position.Type == domain.PositionTypeShort {
+		positionType = domain.PositionTypeLong
+	}
+	margin := min(position.Margin, session.Balance)
+	session.Balance -= margin
+	opened := s.openPositionUnsafe(session, positionType, price, executionMarket, margin, position.Leverage, PositionExits{})
+	return &closedPosition, opened, nil
+}
+
+// findPositionUnsafe returns the session's active position with the given ID,
+// or its only position if positionID is empty. Must be called with s.mu held.
+func findPositionUnsafe(session *domain.PlayerState, positionID string) (*domain.Position, error) {
+	if len(session.ActivePositions) == 0 {
+		return nil, ErrNoActivePosition
+	}
+	if positionID == "" {
+		if len(session.ActivePositions) > 1 {
+			return nil, fmt.Errorf("%w: a position ID is required with several open positions", ErrPositionNotFound)
+		}
+		return session.ActivePositions[0], nil
+	}
+	for _, position := range session.ActivePositions {
+		if position.ID == positionID {
+			return position, nil
+		}
+	}
+	return nil, ErrPositionNotFound
+}
+
+// exitUnsafe prices closing the position at closePrice, returning the exit
+// fill price, the costs of the exit fill and the position's realized PnL net
+// of all its costs. Must be called with s.mu held.
+func (s *PlayerService) exitUnsafe(position *domain.Position, closePrice float64, reason domain.CloseReason) (float64, domain.ExecutionCosts, float64) {
+	kind := executionMarket
+	if reason == domain.CloseReasonTakeProfit {
+		kind = executionLimit
+	}
+	buy := position.Type == domain.PositionTypeShort
+	exitPrice, exitCosts := s.costs.fill(closePrice, buy, position.Quantity*closePrice, kind, s.crowdImbalanceUnsafe(buy))
+	costs := position.Costs.Add(exitCosts)
+
+	pnl := (exitPrice - position.EntryPrice) * position.Quantity
+	if position.Type == domain.PositionTypeShort {
+		pnl *= -1
+	}
+	return exitPrice, exitCosts, math.Max(pnl-costs.Charged(), -position.Margin)
+}
+
+// closePositionUnsafe settles one of the session's active positions at
+// closePrice through the cost model and returns its margin plus PnL to the
+// balance. Take-profits fill at their level like limit orders; every other
+// close crosses the spread. Losses are capped at the margin. Must be called
+// with s.mu held.
+func (s *PlayerService) closePositionUnsafe(session *domain.PlayerState, activePosition *domain.Position, closePrice float64, reason domain.CloseReason) domain.ClosedPosition {
+	exitPrice, exitCosts, pnl := s.exitUnsafe(activePosition, closePrice, reason)
+	costs := activePosition.Costs.Add(exitCosts)
+	pnlPercentage := (pnl / activePosition.Margin) * 100
 
 	closedPosition := domain.ClosedPosition{
 		Position: domain.Position{
-			Quantity:      activePosition.Quantity,
-			Type:          activePosition.Type,
-			EntryPrice:    activePosition.EntryPrice,
-			EntryTime:     activePosition.EntryTime,
-			Pnl:           pnl,
-			PnlPercentage: pnlPercentage,
+			ID:               activePosition.ID,
+			Quantity:         activePosition.Quantity,
+			Type:             activePosition.Type,
+			EntryPrice:       activePosition.EntryPrice,
+			EntryTime:        activePosition.EntryTime,
+			Leverage:         activePosition.Leverage,
+			Margin:           activePosition.Margin,
+			LiquidationPrice: activePosition.LiquidationPrice,
+			StopLoss:         activePosition.StopLoss,
+			TakeProfit:       activePosition.TakeProfit,
+			TrailingStop:     activePosition.TrailingStop,
+			Costs:            costs,
+			Pnl:              pnl,
+			PnlPercentage:    pnlPercentage,
 		},
-		ExitPrice: closePrice,
-		ExitTime:  time.Now(),
+		ExitPrice:   exitPrice,
+		ExitTime:    time.Now(),
+		CloseReason: reason,
 	}
 
 	session.ClosedPositions = append(session.ClosedPositions, closedPosition)
-	session.ActivePosition = nil
-	session.Balance = initialInvestment + pnl
+	s.trades.Record(domain.Trade{
+		ID:          generateUUID(),
+		PlayerID:    session.PlayerId,
+		RoundID:     s.roundID,
+		PositionID:  closedPosition.ID,
+		Action:      domain.TradeActionClose,
+		Type:        closedPosition.Type,
+		Price:       exitPrice,
+		Quantity:    closedPosition.Quantity,
+		Leverage:    closedPosition.Leverage,
+		Margin:      closedPosition.Margin,
+		Fees:        exitCosts.Fees,
+		Funding:     costs.Funding,
+		Pnl:         pnl,
+		CloseReason: reason,
+		Time:        closedPosition.ExitTime,
+	})
+	session.ActivePositions = slices.DeleteFunc(session.ActivePositions, func(position *domain.Position) bool {
+		return position == activePosition
+	})
+	session.Balance += activePosition.Margin + pnl
 
-	return &closedPosition, nil
+	return closedPosition
 }
 
+// calculatePnl returns the position's PnL at currentPrice, net of the fees
+// paid so far, and its percentage of the margin. The loss never exceeds the
+// margin.
 func (s *PlayerService) calculatePnl(position *domain.Position, currentPrice float64) (float64, float64) {
 	pnl := (currentPrice - position.EntryPrice) * position.Quantity
 	if position.Type == domain.PositionTypeShort {
 		pnl *= -1
 	}
-	pnlPercentage := (pnl / (position.Quantity * position.EntryPrice)) * 100
+	pnl = math.Max(pnl-position.Costs.Charged(), -position.Margin)
+	pnlPercentage := (pnl / position.Margin) * 100
 	return pnl, pnlPercentage
 }
 
+// liquidationPrice is the price at which the position's equity, margin plus
+// PnL, falls to the maintenance margin of its notional at that price. Funding
+// paid so far comes out of the margin.
+func (s *PlayerService) liquidationPrice(position *domain.Position) float64 {
+	cushion := (position.Margin - position.Costs.Funding) / position.Margin / position.Leverage
+	if position.Type == domain.PositionTypeShort {
+		return position.EntryPrice * (1 + cushion) / (1 + s.maintenanceMargin)
+	}
+	return max(position.EntryPrice*(1-cushion)/(1-s.maintenanceMargin), 0)
+}
+
+// shouldLiquidate reports whether currentPrice has reached the position's
+// liquidation price.
+func shouldLiquidate(position *domain.Position, currentPrice float64) bool {
+	if position.Type == domain.PositionTypeShort {
+		return currentPrice >= position.LiquidationPrice
+	}
+	return currentPrice <= position.LiquidationPrice
+}
+
 func (s *PlayerService) GetPlayerCount() int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -147,11 +539,17 @@ func (s *PlayerService) GetPlayerCount() int {
 func (s *PlayerService) GetPositionsCount() (int, int) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.positionsCountUnsafe()
+}
+
+// positionsCountUnsafe counts open long and short positions across all
+// players. Must be called with s.mu held.
+func (s *PlayerService) positionsCountUnsafe() (int, int) {
 	longPositions := 0
 	shortPositions := 0
 	for _, session := range s.playerSessions {
-		if session.ActivePosition != nil {
-			if session.ActivePosition.Type == domain.PositionTypeLong {
+		for _, position := range session.ActivePositions {
+			if position.Type == domain.PositionTypeLong {
 				longPositions++
 			} else {
 				shortPositions++
@@ -166,7 +564,10 @@ func (s *PlayerService) ResetAllPlayers() {
 	defer s.mu.Unlock()
 	for _, session := range s.playerSessions {
 		session.Balance = StartingBalance
-		session.ActivePosition = nil
+		session.Reserved = 0
+		session.Trades = 0
+		session.LastOrderAt = time.Time{}
+		session.ActivePositions = []*domain.Position{}
 		session.ClosedPositions = []domain.ClosedPosition{}
 	}
 }
@@ -183,24 +584,66 @@ func (s *PlayerService) GetAllSessions() map[string]*domain.PlayerState {
 	return sessions
 }
 
-// UpdateAllPlayerPnl updates PnL for all players with active positions
-func (s *PlayerService) UpdateAllPlayerPnl(currentPrice float64) bool {
+// UpdateAllPlayerPnl updates PnL for all players with active positions and
+// liquidates those whose equity has fallen below maintenance margin.
+func (s *PlayerService) UpdateAllPlayerPnl(currentPrice float64) (bool, []Liquidation) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	pnlUpdated := false
+	var liquidations []Liquidation
 
-	for _, session := range s.playerSessions {
-		if session.ActivePosition != nil {
-			pnl, pnlPercentage := s.calculatePnl(session.ActivePosition, currentPrice)
-			session.ActivePosition.Pnl = pnl
-			session.ActivePosition.PnlPercentage = pnlPercentage
+	for playerID, session := range s.playerSessions {
+		// Iterate over a copy, since liquidations remove positions.
+		for _, position := range slices.Clone(session.ActivePositions) {
 			pnlUpdated = true
+			if shouldLiquidate(position, currentPrice) {
+				closedPosition := s.closePositionUnsafe(session, position, currentPrice, domain.CloseReasonLiquidation)
+				liquidations = append(liquidations, Liquidation{
+					PlayerID: playerID,
+					Position: closedPosition,
+				})
+				continue
+			}
+			pnl, pnlPercentage := s.calculatePnl(position, currentPrice)
+			position.Pnl = pnl
+			position.PnlPercentage = pnlPercentage
 		}
 	}
-	return pnlUpdated
+	return pnlUpdated, liquidations
 }
 
-// GetPlayerStat returns PnL data for a specific player
+// GetPlayerState returns a copy of the player's balance and positions that
+// stays consistent while the round keeps updating them.
+func (s *PlayerService) GetPlayerState(playerID string) domain.BasePlayerState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, exists := s.playerSessions[playerID]
+	if !exists {
+		return domain.BasePlayerState{
+			ActivePositions: []*domain.Position{},
+			ClosedPositions: []domain.ClosedPosition{},
+		}
+	}
+
+	activePositions := make([]*domain.Position, 0, len(session.ActivePositions))
+	for _, position := range session.ActivePositions {
+		position := *position
+		if position.TrailingStop != nil {
+			trailingStop := *position.TrailingStop
+			position.TrailingStop = &trailingStop
+		}
+		activePositions = append(activePositions, &position)
+	}
+	return domain.BasePlayerState{
+		Balance:         session.Balance,
+		ActivePositions: activePositions,
+		ClosedPositions: slices.Clone(session.ClosedPositions),
+	}
+}
+
+// GetPlayerStat returns PnL data for a specific player. The active PnL covers
+// all open positions, and its percentage is of their combined margin.
 func (s *PlayerService) GetPlayerStat(playerID string) (float64, float64, float64, float64) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -217,10 +660,14 @@ func (s *PlayerService) GetPlayerStat(playerID string) (float64, float64, float6
 
 	balance := session.Balance
 	activePnl := 0.0
+	activeMargin := 0.0
+	for _, position := range session.ActivePositions {
+		activePnl += position.Pnl
+		activeMargin += position.Margin
+	}
 	activePnlPercentage := 0.0
-	if session.ActivePosition != nil {
-		activePnl = session.ActivePosition.Pnl
-		activePnlPercentage = session.ActivePosition.PnlPercentage
+	if activeMargin > 0 {
+		activePnlPercentage = activePnl / activeMargin * 100
 	}
 
 	return totalRealizedPnl, activePnl, balance, activePnlPercentage
@@ -233,9 +680,9 @@ func (s *PlayerService) GetLeaderboard() []domain.LeaderboardPlayer {
 	leaderboard := []domain.LeaderboardPlayer{}
 
 	for _, session := range s.playerSessions {
-		activeBalance := session.Balance
-		if session.ActivePosition != nil {
-			activeBalance += session.ActivePosition.EntryPrice*session.ActivePosition.Quantity + session.ActivePosition.Pnl
+		activeBalance := session.Balance + session.Reserved
+		for _, position := range session.ActivePositions {
+			activeBalance += position.Margin + position.Pnl
 		}
 		leaderboard = append(leaderboard, domain.LeaderboardPlayer{
 			PlayerId:      session.PlayerId,
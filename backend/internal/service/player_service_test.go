@@ -0,0 +1,300 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+// newTestPlayerService returns a service without execution costs or trade
+// limits, with a session for each of players.
+func newTestPlayerService(players ...string) *PlayerService {
+	s := NewPlayerService(config.TradingConfig{}, NewTradeHistory(nil))
+	for _, player := range players {
+		s.GetPlayerSessionOrCreate(player, &player)
+	}
+	return s
+}
+
+func TestGetPlayerStateIsASnapshot(t *testing.T) {
+	s := newTestPlayerService("alice")
+	exits := PositionExits{TrailDistance: 5}
+	for _, positionType := range []domain.PositionType{domain.PositionTypeLong, domain.PositionTypeShort} {
+		if _, err := s.CreatePosition("alice", positionType, 100, PositionSize{Amount: 20, Leverage: 1}, exits, 1); err != nil {
+			t.Fatalf("CreatePosition: %v", err)
+		}
+	}
+
+	state := s.GetPlayerState("alice")
+	if len(state.ActivePositions) != 2 || state.Balance != 60 {
+		t.Fatalf("state = %d positions with balance %v, want 2 with 60", len(state.ActivePositions), state.Balance)
+	}
+	first := state.ActivePositions[0]
+	firstID, firstLevel := first.ID, first.TrailingStop.Level
+
+	s.UpdateAllPlayerPnl(120)
+	if _, err := s.ClosePosition("alice", firstID, 120); err != nil {
+		t.Fatalf("ClosePosition: %v", err)
+	}
+
+	if len(state.ActivePositions) != 2 || state.ActivePositions[0] != first || first.ID != firstID {
+		t.Fatal("closing a position changed the snapshot's positions")
+	}
+	if first.Pnl != 0 || first.TrailingStop.Level != firstLevel {
+		t.Fatalf("snapshot position was updated to pnl %v and trailing level %v", first.Pnl, first.TrailingStop.Level)
+	}
+	if len(state.ClosedPositions) != 0 {
+		t.Fatalf("snapshot gained %d closed positions", len(state.ClosedPositions))
+	}
+}
+
+func TestGetPlayerStateUnknownPlayer(t *testing.T) {
+	state := newTestPlayerService().GetPlayerState("nobody")
+	if state.ActivePositions == nil || state.ClosedPositions == nil || state.Balance != 0 {
+		t.Fatalf("state = %+v, want empty, non-nil position lists", state)
+	}
+}
+
+func TestGetLeaderboardCountsReservedOrderMargin(t *testing.T) {
+	s := newTestPlayerService("alice", "bob")
+	orders := NewOrderService(s)
+	req := OrderRequest{Type: domain.OrderTypeLimit, Side: domain.PositionTypeLong, Price: 90, Size: PositionSize{Fraction: 0.5}}
+	if _, err := orders.PlaceOrder("alice", req, 100, DefaultMaxLeverage); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if _, err := s.CreatePosition("bob", domain.PositionTypeLong, 100, PositionSize{Amount: 50, Leverage: 1}, PositionExits{}, 1); err != nil {
+		t.Fatalf("CreatePosition: %v", err)
+	}
+	s.UpdateAllPlayerPnl(90)
+
+	balances := map[string]float64{}
+	for _, player := range s.GetLeaderboard() {
+		balances[player.PlayerId] = player.ActiveBalance
+	}
+	if balances["alice"] != 100 || balances["bob"] != 95 {
+		t.Fatalf("leaderboard balances = %v, want alice 100 and bob 95", balances)
+	}
+
+	orders.EvaluateOrders(domain.PriceData{Open: 95, High: 95, Low: 89, Close: 90})
+	if session := s.GetPlayerSessionOrCreate("alice", nil); session.Reserved != 0 || len(session.ActivePositions) != 1 {
+		t.Fatalf("after the fill alice has %v reserved and %d positions, want 0 and 1", session.Reserved, len(session.ActivePositions))
+	}
+	for _, player := range s.GetLeaderboard() {
+		if player.PlayerId == "alice" && player.ActiveBalance != 100 {
+			t.Fatalf("alice's balance after the fill = %v, want 100", player.ActiveBalance)
+		}
+	}
+}
+
+func TestReversePositionPricesTheExit(t *testing.T) {
+	// A 10x long of the whole balance pays a 1.0 fee to open and about 0.9 to
+	// close, leaving 100 - (100-price)*10 - 1.0 - 0.01*price to reopen with.
+	tests := []struct {
+		name       string
+		price      float64
+		wantMargin float64
+		wantErr    error
+	}{
+		{name: "enough left after exit costs", price: 90.3, wantMargin: 1.097},
+		{name: "exit costs leave too little", price: 90.22, wantErr: ErrBelowMinNotional},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewPlayerService(config.TradingConfig{Costs: config.CostsConfig{TakerFeeBps: 10}}, NewTradeHistory(nil))
+			name := "alice"
+			session := s.GetPlayerSessionOrCreate(name, &name)
+			if _, err := s.CreatePosition(name, domain.PositionTypeLong, 100, PositionSize{Fraction: 1, Leverage: 10}, PositionExits{}, 10); err != nil {
+				t.Fatalf("CreatePosition: %v", err)
+			}
+
+			_, opened, err := s.ReversePosition(name, "", tt.price)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReversePosition error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(session.ActivePositions) != 1 || session.ActivePositions[0].Type != domain.PositionTypeLong || session.Balance != 0 {
+					t.Fatalf("a failed reverse left %d positions and balance %v, want the long untouched", len(session.ActivePositions), session.Balance)
+				}
+				return
+			}
+			if opened.Type != domain.PositionTypeShort || math.Abs(opened.Margin-tt.wantMargin) > 1e-9 {
+				t.Fatalf("reopened a %s with margin %v, want a short with %v", opened.Type, opened.Margin, tt.wantMargin)
+			}
+		})
+	}
+}
+
+func TestPositionSizeNotional(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    PositionSize
+		balance float64
+		want    float64
+		wantErr error
+	}{
+		{name: "whole balance by default", balance: 80, want: 80},
+		{name: "fixed amount", size: PositionSize{Amount: 25}, balance: 80, want: 25},
+		{name: "fraction of balance", size: PositionSize{Fraction: 0.25}, balance: 80, want: 20},
+		{name: "whole balance as a fraction", size: PositionSize{Fraction: 1}, balance: 80, want: 80},
+		{name: "amount and fraction together", size: PositionSize{Amount: 10, Fraction: 0.5}, balance: 80, wantErr: ErrInvalidPositionSize},
+		{name: "negative amount", size: PositionSize{Amount: -5}, balance: 80, wantErr: ErrInvalidPositionSize},
+		{name: "fraction above one", size: PositionSize{Fraction: 1.5}, balance: 80, wantErr: ErrInvalidPositionSize},
+		{name: "negative fraction", size: PositionSize{Fraction: -0.1}, balance: 80, wantErr: ErrInvalidPositionSize},
+		{name: "amount below the minimum", size: PositionSize{Amount: 0.5}, balance: 80, wantErr: ErrBelowMinNotional},
+		{name: "fraction below the minimum", size: PositionSize{Fraction: 0.01}, balance: 80, wantErr: ErrBelowMinNotional},
+		{name: "remaining balance below the minimum", balance: 0.5, wantErr: ErrBelowMinNotional},
+		{name: "amount above balance", size: PositionSize{Amount: 81}, balance: 80, wantErr: ErrInsufficientFunds},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.size.notional(tt.balance)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("notional() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("notional() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatePositionSizing(t *testing.T) {
+	s := newTestPlayerService("alice")
+	session := s.GetPlayerSessionOrCreate("alice", nil)
+
+	sizes := []struct {
+		size        PositionSize
+		wantMargin  float64
+		wantBalance float64
+		wantErr     error
+	}{
+		{size: PositionSize{Fraction: 0.5}, wantMargin: 50, wantBalance: 50},
+		{size: PositionSize{Amount: 20}, wantMargin: 20, wantBalance: 30},
+		{size: PositionSize{Amount: 40}, wantBalance: 30, wantErr: ErrInsufficientFunds},
+		{size: PositionSize{Fraction: 0.5}, wantMargin: 15, wantBalance: 15},
+		{wantMargin: 15, wantBalance: 0},
+		{size: PositionSize{Amount: 1}, wantErr: ErrNoBalance},
+	}
+	for i, step := range sizes {
+		position, err := s.CreatePosition("alice", domain.PositionTypeLong, 100, step.size, PositionExits{}, 1)
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("position %d: error = %v, want %v", i, err, step.wantErr)
+		}
+		if err == nil && (position.Margin != step.wantMargin || position.Quantity != step.wantMargin/100) {
+			t.Errorf("position %d: margin %v for %v units, want %v", i, position.Margin, position.Quantity, step.wantMargin)
+		}
+		if session.Balance != step.wantBalance {
+			t.Errorf("position %d: balance = %v, want %v", i, session.Balance, step.wantBalance)
+		}
+	}
+}
+
+func TestPositionSizeLeverage(t *testing.T) {
+	tests := []struct {
+		name     string
+		leverage float64
+		want     float64
+		wantErr  error
+	}{
+		{name: "unset means 1x", want: 1},
+		{name: "within the cap", leverage: 4, want: 4},
+		{name: "at the cap", leverage: 10, want: 10},
+		{name: "above the cap", leverage: 11, wantErr: ErrInvalidLeverage},
+		{name: "below 1x", leverage: 0.5, wantErr: ErrInvalidLeverage},
+		{name: "negative", leverage: -2, wantErr: ErrInvalidLeverage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PositionSize{Leverage: tt.leverage}.leverage(10)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Fatalf("leverage() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLiquidationPrice(t *testing.T) {
+	tests := []struct {
+		name         string
+		positionType domain.PositionType
+		leverage     float64
+		funding      float64
+		want         float64
+	}{
+		// At the liquidation price the equity, margin plus PnL, equals the
+		// 0.5% maintenance margin of the notional at that price.
+		{name: "10x long", positionType: domain.PositionTypeLong, leverage: 10, want: 90 / 0.995},
+		{name: "10x short", positionType: domain.PositionTypeShort, leverage: 10, want: 110 / 1.005},
+		{name: "2x long", positionType: domain.PositionTypeLong, leverage: 2, want: 50 / 0.995},
+		{name: "1x long is never liquidated above zero", positionType: domain.PositionTypeLong, leverage: 1, want: 0},
+		{name: "funding paid narrows the cushion", positionType: domain.PositionTypeLong, leverage: 10, funding: 2, want: 92 / 0.995},
+		{name: "funding received widens the cushion", positionType: domain.PositionTypeShort, leverage: 10, funding: -1, want: 111 / 1.005},
+	}
+
+	s := newTestPlayerService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position := &domain.Position{
+				Type:       tt.positionType,
+				EntryPrice: 100,
+				Leverage:   tt.leverage,
+				Margin:     10,
+				Costs:      domain.ExecutionCosts{Funding: tt.funding},
+			}
+			if got := s.liquidationPrice(position); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("liquidationPrice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateAllPlayerPnlLiquidates(t *testing.T) {
+	tests := []struct {
+		name           string
+		positionType   domain.PositionType
+		price          float64
+		wantLiquidated bool
+		wantBalance    float64
+		wantPnl        float64
+	}{
+		{name: "long above its liquidation price", positionType: domain.PositionTypeLong, price: 91, wantBalance: 90, wantPnl: -9},
+		{name: "long at its liquidation price", positionType: domain.PositionTypeLong, price: 90 / 0.995, wantLiquidated: true, wantBalance: 90 / 0.995},
+		{name: "long gapping through it loses only the margin", positionType: domain.PositionTypeLong, price: 80, wantLiquidated: true, wantBalance: 90},
+		{name: "short below its liquidation price", positionType: domain.PositionTypeShort, price: 109, wantBalance: 90, wantPnl: -9},
+		{name: "short past its liquidation price", positionType: domain.PositionTypeShort, price: 109.5, wantLiquidated: true, wantBalance: 90.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestPlayerService("alice")
+			session := s.GetPlayerSessionOrCreate("alice", nil)
+			if _, err := s.CreatePosition("alice", tt.positionType, 100, PositionSize{Amount: 10, Leverage: 10}, PositionExits{}, 10); err != nil {
+				t.Fatalf("CreatePosition: %v", err)
+			}
+
+			_, liquidations := s.UpdateAllPlayerPnl(tt.price)
+
+			if got := len(liquidations) == 1; got != tt.wantLiquidated {
+				t.Fatalf("liquidated = %v, want %v", got, tt.wantLiquidated)
+			}
+			if math.Abs(session.Balance-tt.wantBalance) > 1e-9 {
+				t.Errorf("balance = %v, want %v", session.Balance, tt.wantBalance)
+			}
+			if tt.wantLiquidated {
+				closed := liquidations[0].Position
+				if liquidations[0].PlayerID != "alice" || closed.CloseReason != domain.CloseReasonLiquidation || len(session.ActivePositions) != 0 {
+					t.Fatalf("liquidation = %+v with %d positions left, want alice's position closed by liquidation", liquidations[0], len(session.ActivePositions))
+				}
+				return
+			}
+			if pnl := session.ActivePositions[0].Pnl; math.Abs(pnl-tt.wantPnl) > 1e-9 {
+				t.Errorf("pnl = %v, want %v", pnl, tt.wantPnl)
+			}
+		})
+	}
+}
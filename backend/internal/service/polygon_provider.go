@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"time"
+	"tradeoff/backend/internal/domain"
+
+	polygon "github.com/polygon-io/client-go/rest"
+	"github.com/polygon-io/client-go/rest/models"
+)
+
+// PolygonProvider loads aggregates from the Polygon.io REST API.
+type PolygonProvider struct {
+	client *polygon.Client
+}
+
+func NewPolygonProvider(apiKey string) *PolygonProvider {
+	return &PolygonProvider{
+		client: polygon.New(apiKey),
+	}
+}
+
+func polygonPriceDataToDomain(p models.Agg) domain.PriceData {
+	return domain.PriceData{
+		Time:   time.Time(p.Timestamp).Unix(),
+		Open:   p.Open,
+		High:   p.High,
+		Low:    p.Low,
+		Close:  p.Close,
+		Volume: p.Volume,
+	}
+}
+
+func (p *PolygonProvider) LoadAggregates(ctx context.Context, query AggregatesQuery) ([]domain.PriceData, error) {
+	multiplier := query.Key().Multiplier
+
+	priceData := []domain.PriceData{}
+	params := models.ListAggsParams{
+		Ticker:     query.Ticker,
+		Multiplier: multiplier,
+		Timespan:   models.Timespan(query.Timespan),
+		From:       models.Millis(query.From),
+		To:         models.Millis(query.To),
+	}.
+		WithAdjusted(true).
+		WithOrder(models.Order("asc"))
+	if query.Limit != nil {
+		params = params.
+			WithLimit(*query.Limit)
+	}
+
+	iter := p.client.ListAggs(ctx, params)
+
+	for iter.Next() {
+		agg := iter.Item()
+		priceData = append(priceData, polygonPriceDataToDomain(agg))
+	}
+	if iter.Err() != nil {
+		return nil, iter.Err()
+	}
+
+	return priceData, nil
+}
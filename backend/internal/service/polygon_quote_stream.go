@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"tradeoff/backend/internal/domain"
+
+	polygonws "github.com/polygon-io/client-go/websocket"
+	"github.com/polygon-io/client-go/websocket/models"
+)
+
+// PolygonQuoteStream streams per-second crypto aggregates from Polygon's
+// real-time WebSocket feed.
+type PolygonQuoteStream struct {
+	apiKey string
+}
+
+func NewPolygonQuoteStream(apiKey string) *PolygonQuoteStream {
+	return &PolygonQuoteStream{
+		apiKey: apiKey,
+	}
+}
+
+// polygonCryptoPair converts a REST crypto ticker such as X:BTCUSD to the
+// BTC-USD pair format used by the streaming API.
+func polygonCryptoPair(ticker string) (string, error) {
+	symbol, ok := strings.CutPrefix(ticker, "X:")
+	if !ok || len(symbol) <= 3 {
+		return "", fmt.Errorf("polygon quote feed only supports crypto tickers, got %q", ticker)
+	}
+	return symbol[:len(symbol)-3] + "-" + symbol[len(symbol)-3:], nil
+}
+
+func (s *PolygonQuoteStream) Subscribe(ctx context.Context, ticker string) (<-chan domain.PriceData, error) {
+	pair, err := polygonCryptoPair(ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := polygonws.New(polygonws.Config{
+		APIKey: s.apiKey,
+		Feed:   polygonws.RealTime,
+		Market: polygonws.Crypto,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Connect(); err != nil {
+		return nil, err
+	}
+	if err := client.Subscribe(polygonws.CryptoSecAggs, pair); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	bars := make(chan domain.PriceData, 64)
+	go func() {
+		defer close(bars)
+		defer client.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-client.Error():
+				log.Printf("Polygon quote feed error for %s: %v", pair, err)
+				return
+			case out, ok := <-client.Output():
+				if !ok {
+					return
+				}
+				agg, ok := out.(models.CurrencyAgg)
+				if !ok {
+					continue
+				}
+				bar := domain.PriceData{
+					Time:   agg.StartTimestamp / 1000,
+					Open:   agg.Open,
+					High:   agg.High,
+					Low:    agg.Low,
+					Close:  agg.Close,
+					Volume: agg.Volume,
+				}
+				select {
+				case bars <- bar:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return bars, nil
+}
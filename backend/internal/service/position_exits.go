@@ -0,0 +1,202 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"tradeoff/backend/internal/domain"
+)
+
+// PositionExits are the stop-loss and take-profit levels of a position and
+// its trailing stop, which trails the best price by TrailDistance or by
+// TrailPercent of it. A zero value is not set.
+type PositionExits struct {
+	StopLoss      float64
+	TakeProfit    float64
+	TrailDistance float64
+	TrailPercent  float64
+}
+
+// OrderFill is a position closed because one of its exit levels was reached.
+type OrderFill struct {
+	PlayerID string
+	Position domain.ClosedPosition
+}
+
+// validate checks that each set level is on the correct side of price for a
+// position of positionType, so it cannot trigger straight away.
+func (exits PositionExits) validate(positionType domain.PositionType, price float64) error {
+	if exits.StopLoss < 0 || exits.TakeProfit < 0 {
+		return fmt.Errorf("%w: levels must be positive", ErrInvalidExitLevel)
+	}
+	if exits.TrailDistance < 0 || exits.TrailPercent < 0 {
+		return fmt.Errorf("%w: trailing distance must be positive", ErrInvalidExitLevel)
+	}
+	if exits.TrailDistance != 0 && exits.TrailPercent != 0 {
+		return fmt.Errorf("%w: set either a trailing distance or a trailing percent, not both", ErrInvalidExitLevel)
+	}
+	if exits.TrailPercent >= 100 || (positionType == domain.PositionTypeLong && exits.TrailDistance >= price) {
+		return fmt.Errorf("%w: trailing stop must stay above zero", ErrInvalidExitLevel)
+	}
+
+	if positionType == domain.PositionTypeShort {
+		if exits.StopLoss != 0 && exits.StopLoss <= price {
+			return fmt.Errorf("%w: stop-loss of a short must be above %.2f", ErrInvalidExitLevel, price)
+		}
+		if exits.TakeProfit != 0 && exits.TakeProfit >= price {
+			return fmt.Errorf("%w: take-profit of a short must be below %.2f", ErrInvalidExitLevel, price)
+		}
+		return nil
+	}
+
+	if exits.StopLoss != 0 && exits.StopLoss >= price {
+		return fmt.Errorf("%w: stop-loss of a long must be below %.2f", ErrInvalidExitLevel, price)
+	}
+	if exits.TakeProfit != 0 && exits.TakeProfit <= price {
+		return fmt.Errorf("%w: take-profit of a long must be above %.2f", ErrInvalidExitLevel, price)
+	}
+	return nil
+}
+
+// trailingStop returns the trailing stop the exits describe for a position of
+// positionType at price, or nil if none is set.
+func (exits PositionExits) trailingStop(positionType domain.PositionType, price float64) *domain.TrailingStop {
+	if exits.TrailDistance == 0 && exits.TrailPercent == 0 {
+		return nil
+	}
+	stop := &domain.TrailingStop{
+		Distance: exits.TrailDistance,
+		Percent:  exits.TrailPercent,
+	}
+	stop.Level = trailLevel(stop, positionType, price)
+	return stop
+}
+
+// trailLevel is the stop level trailing the given best price.
+func trailLevel(stop *domain.TrailingStop, positionType domain.PositionType, best float64) float64 {
+	distance := stop.Distance
+	if stop.Percent != 0 {
+		distance = best * stop.Percent / 100
+	}
+	if positionType == domain.PositionTypeShort {
+		return best + distance
+	}
+	return best - distance
+}
+
+// ratchetTrailingStop moves the position's trailing stop towards the bar's
+// high for a long, or its low for a short. The stop never moves back.
+func ratchetTrailingStop(position *domain.Position, bar domain.PriceData) {
+	stop := position.TrailingStop
+	if stop == nil {
+		return
+	}
+	if position.Type == domain.PositionTypeShort {
+		stop.Level = min(stop.Level, trailLevel(stop, position.Type, bar.Low))
+		return
+	}
+	stop.Level = max(stop.Level, trailLevel(stop, position.Type, bar.High))
+}
+
+// SetPositionExits replaces the exit levels of the player's position with the
+// given ID, or of their only position if positionID is empty. Levels are
+// validated against the current price.
+func (s *PlayerService) SetPositionExits(playerID string, positionID string, exits PositionExits, currentPrice float64) (*domain.Position, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.playerSessions[playerID]
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+
+	position, err := findPositionUnsafe(session, positionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := exits.validate(position.Type, currentPrice); err != nil {
+		return nil, err
+	}
+
+	position.StopLoss = exits.StopLoss
+	position.TakeProfit = exits.TakeProfit
+	position.TrailingStop = exits.trailingStop(position.Type, currentPrice)
+	updated := *position
+	return &updated, nil
+}
+
+// EvaluateTriggers closes every position whose stop-loss, trailing stop or
+// take-profit lies within the bar's range, filling it at the trigger price.
+// If a bar reaches several levels the stops are assumed to have been hit
+// first. Trailing stops of the positions left open then ratchet with the bar.
+func (s *PlayerService) EvaluateTriggers(bar domain.PriceData) []OrderFill {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var fills []OrderFill
+	for playerID, session := range s.playerSessions {
+		// Iterate over a copy, since fills remove positions.
+		for _, position := range slices.Clone(session.ActivePositions) {
+			price, reason, triggered := exitTrigger(position, bar)
+			if !triggered {
+				ratchetTrailingStop(position, bar)
+				continue
+			}
+			fills = append(fills, OrderFill{
+				PlayerID: playerID,
+				Position: s.closePositionUnsafe(session, position, price, reason),
+			})
+		}
+	}
+	return fills
+}
+
+// exitTrigger returns the level the bar reached, if any, and why it closes
+// the position.
+func exitTrigger(position *domain.Position, bar domain.PriceData) (float64, domain.CloseReason, bool) {
+	if position.Type == domain.PositionTypeShort {
+		switch {
+		case position.StopLoss != 0 && bar.High >= position.StopLoss:
+			return position.StopLoss, domain.CloseReasonStopLoss, true
+		case position.TrailingStop != nil && bar.High >= position.TrailingStop.Level:
+			return position.TrailingStop.Level, domain.CloseReasonTrailingStop, true
+		case position.TakeProfit != 0 && bar.Low <= position.TakeProfit:
+			return position.TakeProfit, domain.CloseReasonTakeProfit, true
+		}
+		return 0, "", false
+	}
+
+	switch {
+	case position.StopLoss != 0 && bar.Low <= position.StopLoss:
+		return position.StopLoss, domain.CloseReasonStopLoss, true
+	case position.TrailingStop != nil && bar.Low <= position.TrailingStop.Level:
+		return position.TrailingStop.Level, domain.CloseReasonTrailingStop, true
+	case position.TakeProfit != 0 && bar.High >= position.TakeProfit:
+		return position.TakeProfit, domain.CloseReasonTakeProfit, true
+	}
+	return 0, "", false
+}
+
+// GetTrailingStops returns the current trailing stop level of each of the
+// player's positions that has one, keyed by position ID.
+func (s *PlayerService) GetTrailingStops(playerID string) map[string]float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, exists := s.playerSessions[playerID]
+	if !exists {
+		return nil
+	}
+
+	var levels map[string]float64
+	for _, position := range session.ActivePositions {
+		if position.TrailingStop == nil {
+			continue
+		}
+		if levels == nil {
+			levels = make(map[string]float64)
+		}
+		levels[position.ID] = position.TrailingStop.Level
+	}
+	return levels
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"tradeoff/backend/internal/domain"
+)
+
+func TestPositionExitsValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		positionType domain.PositionType
+		exits        PositionExits
+		wantErr      error
+	}{
+		{name: "no levels", positionType: domain.PositionTypeLong},
+		{name: "long with levels either side", positionType: domain.PositionTypeLong, exits: PositionExits{StopLoss: 95, TakeProfit: 110}},
+		{name: "short with levels either side", positionType: domain.PositionTypeShort, exits: PositionExits{StopLoss: 105, TakeProfit: 90}},
+		{name: "long stop-loss above price", positionType: domain.PositionTypeLong, exits: PositionExits{StopLoss: 101}, wantErr: ErrInvalidExitLevel},
+		{name: "long stop-loss at price", positionType: domain.PositionTypeLong, exits: PositionExits{StopLoss: 100}, wantErr: ErrInvalidExitLevel},
+		{name: "long take-profit below price", positionType: domain.PositionTypeLong, exits: PositionExits{TakeProfit: 99}, wantErr: ErrInvalidExitLevel},
+		{name: "short stop-loss below price", positionType: domain.PositionTypeShort, exits: PositionExits{StopLoss: 99}, wantErr: ErrInvalidExitLevel},
+		{name: "short take-profit above price", positionType: domain.PositionTypeShort, exits: PositionExits{TakeProfit: 101}, wantErr: ErrInvalidExitLevel},
+		{name: "negative level", positionType: domain.PositionTypeLong, exits: PositionExits{StopLoss: -1}, wantErr: ErrInvalidExitLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.exits.validate(tt.positionType, 100); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluateTriggersFillsStopsAndTakeProfits(t *testing.T) {
+	tests := []struct {
+		name         string
+		positionType domain.PositionType
+		exits        PositionExits
+		bar          domain.PriceData
+		wantReason   domain.CloseReason
+		wantPrice    float64
+	}{
+		{
+			name:         "bar inside the levels",
+			positionType: domain.PositionTypeLong,
+			exits:        PositionExits{StopLoss: 95, TakeProfit: 110},
+			bar:          domain.PriceData{Open: 100, High: 109, Low: 96, Close: 101},
+		},
+		{
+			name:         "long stop-loss fills at its level",
+			positionType: domain.PositionTypeLong,
+			exits:        PositionExits{StopLoss: 95, TakeProfit: 110},
+			bar:          domain.PriceData{Open: 100, High: 101, Low: 90, Close: 92},
+			wantReason:   domain.CloseReasonStopLoss,
+			wantPrice:    95,
+		},
+		{
+			name:         "long take-profit fills at its level",
+			positionType: domain.PositionTypeLong,
+			exits:        PositionExits{StopLoss: 95, TakeProfit: 110},
+			bar:          domain.PriceData{Open: 100, High: 112, Low: 99, Close: 111},
+			wantReason:   domain.CloseReasonTakeProfit,
+			wantPrice:    110,
+		},
+		{
+			name:         "short stop-loss fills at its level",
+			positionType: domain.PositionTypeShort,
+			exits:        PositionExits{StopLoss: 105, TakeProfit: 90},
+			bar:          domain.PriceData{Open: 100, High: 106, Low: 99, Close: 104},
+			wantReason:   domain.CloseReasonStopLoss,
+			wantPrice:    105,
+		},
+		{
+			name:         "short take-profit fills at its level",
+			positionType: domain.PositionTypeShort,
+			exits:        PositionExits{StopLoss: 105, TakeProfit: 90},
+			bar:          domain.PriceData{Open: 100, High: 101, Low: 89, Close: 90},
+			wantReason:   domain.CloseReasonTakeProfit,
+			wantPrice:    90,
+		},
+		{
+			name:         "stop-loss wins when a bar reaches both",
+			positionType: domain.PositionTypeLong,
+			exits:        PositionExits{StopLoss: 95, TakeProfit: 110},
+			bar:          domain.PriceData{Open: 100, High: 111, Low: 94, Close: 100},
+			wantReason:   domain.CloseReasonStopLoss,
+			wantPrice:    95,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestPlayerService("alice")
+			session := s.GetPlayerSessionOrCreate("alice", nil)
+			if _, err := s.CreatePosition("alice", tt.positionType, 100, PositionSize{Amount: 50}, tt.exits, 1); err != nil {
+				t.Fatalf("CreatePosition: %v", err)
+			}
+
+			fills := s.EvaluateTriggers(tt.bar)
+
+			if tt.wantReason == "" {
+				if len(fills) != 0 || len(session.ActivePositions) != 1 {
+					t.Fatalf("got %d fills with %d positions open, want the position untouched", len(fills), len(session.ActivePositions))
+				}
+				return
+			}
+			if len(fills) != 1 || len(session.ActivePositions) != 0 {
+				t.Fatalf("got %d fills with %d positions open, want the position closed", len(fills), len(session.ActivePositions))
+			}
+			closed := fills[0].Position
+			if fills[0].PlayerID != "alice" || closed.CloseReason != tt.wantReason || closed.ExitPrice != tt.wantPrice {
+				t.Fatalf("fill = %s closed by %q at %v, want %q at %v", fills[0].PlayerID, closed.CloseReason, closed.ExitPrice, tt.wantReason, tt.wantPrice)
+			}
+			// Half a unit was bought at 100, without costs.
+			wantPnl := (tt.wantPrice - 100) * 0.5
+			if tt.positionType == domain.PositionTypeShort {
+				wantPnl *= -1
+			}
+			if closed.Pnl != wantPnl || session.Balance != StartingBalance+wantPnl {
+				t.Errorf("pnl %v leaves balance %v, want %v and %v", closed.Pnl, session.Balance, wantPnl, StartingBalance+wantPnl)
+			}
+		})
+	}
+}
+
+func TestPositionExitsTrailingStop(t *testing.T) {
+	tests := []struct {
+		name         string
+		positionType domain.PositionType
+		exits        PositionExits
+		want         *domain.TrailingStop
+	}{
+		{name: "none set", positionType: domain.PositionTypeLong},
+		{name: "long by distance", positionType: domain.PositionTypeLong, exits: PositionExits{TrailDistance: 5}, want: &domain.TrailingStop{Distance: 5, Level: 95}},
+		{name: "short by distance", positionType: domain.PositionTypeShort, exits: PositionExits{TrailDistance: 5}, want: &domain.TrailingStop{Distance: 5, Level: 105}},
+		{name: "long by percent", positionType: domain.PositionTypeLong, exits: PositionExits{TrailPercent: 10}, want: &domain.TrailingStop{Percent: 10, Level: 90}},
+		{name: "short by percent", positionType: domain.PositionTypeShort, exits: PositionExits{TrailPercent: 10}, want: &domain.TrailingStop{Percent: 10, Level: 110}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.exits.trailingStop(tt.positionType, 100)
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Fatalf("trailingStop() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPositionExitsValidateTrailingStop(t *testing.T) {
+	tests := []struct {
+		name         string
+		positionType domain.PositionType
+		exits        PositionExits
+		wantErr      error
+	}{
+		{name: "distance", positionType: domain.PositionTypeLong, exits: PositionExits{TrailDistance: 5}},
+		{name: "percent", positionType: domain.PositionTypeShort, exits: PositionExits{TrailPercent: 5}},
+		{name: "distance and percent together", positionType: domain.PositionTypeLong, exits: PositionExits{TrailDistance: 5, TrailPercent: 5}, wantErr: ErrInvalidExitLevel},
+		{name: "negative distance", positionType: domain.PositionTypeLong, exits: PositionExits{TrailDistance: -5}, wantErr: ErrInvalidExitLevel},
+		{name: "long distance down to zero", positionType: domain.PositionTypeLong, exits: PositionExits{TrailDistance: 100}, wantErr: ErrInvalidExitLevel},
+		{name: "short distance beyond the price", positionType: domain.PositionTypeShort, exits: PositionExits{TrailDistance: 150}},
+		{name: "percent of 100", positionType: domain.PositionTypeLong, exits: PositionExits{TrailPercent: 100}, wantErr: ErrInvalidExitLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.exits.validate(tt.positionType, 100); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluateTriggersTrailsStops(t *testing.T) {
+	tests := []struct {
+		name         string
+		positionType domain.PositionType
+		exits        PositionExits
+		bars         []domain.PriceData
+		wantLevels   []float64
+		wantExit     float64
+	}{
+		{
+			name:         "long ratchets up with highs and never back",
+			positionType: domain.PositionTypeLong,
+			exits:        PositionExits{TrailDistance: 5},
+			bars: []domain.PriceData{
+				{High: 110, Low: 99, Close: 108},
+				{High: 107, Low: 106, Close: 106},
+			},
+			wantLevels: []float64{105, 105},
+		},
+		{
+			name:         "long checks the bar's low before its high moves the stop",
+			positionType: domain.PositionTypeLong,
+			exits:        PositionExits{TrailDistance: 5},
+			bars: []domain.PriceData{
+				{High: 120, Low: 96, Close: 118},
+			},
+			wantLevels: []float64{115},
+		},
+		{
+			name:         "long fills at the trailed level",
+			positionType: domain.PositionTypeLong,
+			exits:        PositionExits{TrailDistance: 5},
+			bars: []domain.PriceData{
+				{High: 110, Low: 101, Close: 109},
+				{High: 109, Low: 103, Close: 104},
+			},
+			wantLevels: []float64{105},
+			wantExit:   105,
+		},
+		{
+			name:         "short trails lows by percent",
+			positionType: domain.PositionTypeShort,
+			exits:        PositionExits{TrailPercent: 10},
+			bars: []domain.PriceData{
+				{High: 101, Low: 90, Close: 92},
+				{High: 95, Low: 91, Close: 94},
+			},
+			wantLevels: []float64{99, 99},
+		},
+		{
+			name:         "short fills at the trailed level",
+			positionType: domain.PositionTypeShort,
+			exits:        PositionExits{TrailPercent: 10},
+			bars: []domain.PriceData{
+				{High: 101, Low: 90, Close: 92},
+				{High: 100, Low: 93, Close: 99},
+			},
+			wantLevels: []float64{99},
+			wantExit:   99,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestPlayerService("alice")
+			position, err := s.CreatePosition("alice", tt.positionType, 100, PositionSize{Amount: 50}, tt.exits, 1)
+			if err != nil {
+				t.Fatalf("CreatePosition: %v", err)
+			}
+
+			var fills []OrderFill
+			for i, bar := range tt.bars {
+				if fills = s.EvaluateTriggers(bar); len(fills) > 0 {
+					break
+				}
+				if level := s.GetTrailingStops("alice")[position.ID]; level != tt.wantLevels[i] {
+					t.Fatalf("after bar %d the stop is at %v, want %v", i, level, tt.wantLevels[i])
+				}
+			}
+
+			if tt.wantExit == 0 {
+				if len(fills) != 0 {
+					t.Fatalf("stop filled at %v, want it left open", fills[0].Position.ExitPrice)
+				}
+				return
+			}
+			if len(fills) != 1 || fills[0].Position.CloseReason != domain.CloseReasonTrailingStop || fills[0].Position.ExitPrice != tt.wantExit {
+				t.Fatalf("fills = %+v, want one trailing stop fill at %v", fills, tt.wantExit)
+			}
+		})
+	}
+}
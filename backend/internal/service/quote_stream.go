@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+const (
+	QuoteFeedPolygon   = "polygon"
+	QuoteFeedWebSocket = "websocket"
+)
+
+// QuoteStream delivers live aggregates for a ticker as they form. The returned
+// channel is closed when ctx is done or the feed fails.
+type QuoteStream interface {
+	Subscribe(ctx context.Context, ticker string) (<-chan domain.PriceData, error)
+}
+
+// NewQuoteStream builds the feed selected in the live round config. It returns
+// nil when no feed is configured, which disables live rounds.
+func NewQuoteStream(cfg config.LiveConfig, polygonAPIKey string) (QuoteStream, error) {
+	switch cfg.Feed {
+	case "":
+		return nil, nil
+	case QuoteFeedPolygon:
+		if polygonAPIKey == "" {
+			return nil, fmt.Errorf("polygon quote feed requires an API key")
+		}
+		return NewPolygonQuoteStream(polygonAPIKey), nil
+	case QuoteFeedWebSocket:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("websocket quote feed requires a URL")
+		}
+		return NewWebSocketQuoteStream(cfg.URL), nil
+	default:
+		return nil, fmt.Errorf("unknown quote feed %q", cfg.Feed)
+	}
+}
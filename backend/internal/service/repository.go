@@ -1,9 +1,44 @@
 package service
 
-import "tradeoff/backend/internal/domain"
+import (
+	"time"
+	"tradeoff/backend/internal/domain"
+)
 
+// PlayerRepository persists player accounts. FindPlayer returns nil if there
+// is no player with the ID.
 type PlayerRepository interface {
 	CreatePlayer(player domain.Player) (domain.Player, error)
 	UpdatePlayer(player domain.Player) (domain.Player, error)
 	FindPlayerByRefreshToken(refreshToken string) (domain.Player, error)
+	FindPlayer(id string) (*domain.Player, error)
+}
+
+// TradeRepository persists every opened and closed position. ListTrades
+// returns a player's trades, most recent first, and TallyClosedTrades counts
+// their closed long and short positions.
+type TradeRepository interface {
+	SaveTrades(trades []domain.Trade) error
+	ListTrades(playerID string, limit int, offset int) ([]domain.Trade, error)
+	TallyClosedTrades(playerID string) (long domain.TradeTally, short domain.TradeTally, err error)
+}
+
+// RoundRepository persists the record of each round and its players'
+// results. FindRound returns nil if there is no round with the ID, and
+// ListRounds returns the rounds in the given phase, most recent first.
+// ListRoundResults returns a player's results, oldest first.
+type RoundRepository interface {
+	SaveRound(round domain.Round) error
+	FindRound(id string) (*domain.Round, error)
+	ListRounds(phase domain.Phase, limit int, offset int) ([]domain.Round, error)
+	SaveRoundResults(results []domain.RoundResult) error
+	ListRoundResults(playerID string) ([]domain.RoundResult, error)
+}
+
+// AggregateRepository persists historical bars together with the time ranges
+// that have already been fetched, so repeated windows are not downloaded again.
+type AggregateRepository interface {
+	FindAggregates(key domain.AggregateKey, from time.Time, to time.Time) ([]domain.PriceData, error)
+	FindAggregateRanges(key domain.AggregateKey) ([]domain.TimeRange, error)
+	SaveAggregates(key domain.AggregateKey, coverage domain.TimeRange, bars []domain.PriceData) error
 }
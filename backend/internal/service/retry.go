@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+	"tradeoff/backend/internal/config"
+)
+
+const (
+	DefaultRetryAttempts  = 4
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultRetryMaxDelay  = 8 * time.Second
+)
+
+// retryPolicy retries with exponential backoff and full jitter: the wait
+// before attempt n is uniform in [0, min(maxDelay, baseDelay*2^(n-1))].
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+func newRetryPolicy(cfg config.RetryConfig) retryPolicy {
+	policy := retryPolicy{
+		maxAttempts: cfg.MaxAttempts,
+		baseDelay:   cfg.BaseDelay,
+		maxDelay:    cfg.MaxDelay,
+	}
+	if policy.maxAttempts <= 0 {
+		policy.maxAttempts = DefaultRetryAttempts
+	}
+	if policy.baseDelay <= 0 {
+		policy.baseDelay = DefaultRetryBaseDelay
+	}
+	if policy.maxDelay <= 0 {
+		policy.maxDelay = DefaultRetryMaxDelay
+	}
+	return policy
+}
+
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.maxDelay
+	if shift := attempt - 1; shift < 30 {
+		ceiling = min(p.maxDelay, p.baseDelay<<shift)
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
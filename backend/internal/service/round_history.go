@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"tradeoff/backend/internal/domain"
+)
+
+const (
+	DefaultRoundsPageSize = 20
+	MaxRoundsPageSize     = 100
+)
+
+// RoundHistory stores the record of each round as it moves through its phases,
+// along with its players' results, and serves the rounds that have finished.
+// Records are written in order by a single background writer, so the round
+// loop never waits on the database.
+type RoundHistory struct {
+	repository RoundRepository
+	writes     *writeQueue[roundWrite]
+}
+
+// roundWrite is a queued round record or, if results is set, the results of a
+// settled round.
+type roundWrite struct {
+	round   domain.Round
+	results []domain.RoundResult
+}
+
+func NewRoundHistory(repository RoundRepository) *RoundHistory {
+	return &RoundHistory{
+		repository: repository,
+		writes:     newWriteQueue[roundWrite](),
+	}
+}
+
+// Run writes queued records until ctx is cancelled, then writes the records
+// still queued and returns.
+func (h *RoundHistory) Run(ctx context.Context) {
+	h.writes.run(ctx, func(writes []roundWrite) {
+		for _, write := range writes {
+			if write.results != nil {
+				if err := h.repository.SaveRoundResults(write.results); err != nil {
+					log.Printf("Error saving %d results of round %s: %v", len(write.results), write.results[0].RoundID, err)
+				}
+				continue
+			}
+			if err := h.repository.SaveRound(write.round); err != nil {
+				log.Printf("Error saving round %s: %v", write.round.ID, err)
+			}
+		}
+	})
+}
+
+// Record queues the current state of a round to be saved.
+func (h *RoundHistory) Record(round domain.Round) {
+	h.writes.push(roundWrite{round: round})
+}
+
+// RecordResults queues the results of a settled round to be saved.
+func (h *RoundHistory) RecordResults(results []domain.RoundResult) {
+	if len(results) == 0 {
+		return
+	}
+	h.writes.push(roundWrite{results: results})
+}
+
+// ListRounds returns a page of finished rounds, most recent first.
+func (h *RoundHistory) ListRounds(limit int, offset int) ([]domain.Round, error) {
+	if limit <= 0 {
+		limit = DefaultRoundsPageSize
+	}
+	limit = min(limit, MaxRoundsPageSize)
+	return h.repository.ListRounds(domain.Closed, limit, max(offset, 0))
+}
+
+// GetRound returns a finished round. Rounds still being played are not
+// returned, since their record would reveal the asset.
+func (h *RoundHistory) GetRound(id string) (domain.Round, error) {
+	round, err := h.repository.FindRound(id)
+	if err != nil {
+		return domain.Round{}, err
+	}
+	if round == nil || round.Phase != domain.Closed {
+		return domain.Round{}, fmt.Errorf("%w: %s", ErrRoundNotFound, id)
+	}
+	return *round, nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"tradeoff/backend/internal/domain"
+)
+
+type savedRounds struct {
+	RoundRepository
+	writes []string
+}
+
+func (r *savedRounds) SaveRound(round domain.Round) error {
+	r.writes = append(r.writes, "round "+round.ID+" "+string(round.Phase))
+	return nil
+}
+
+func (r *savedRounds) SaveRoundResults(results []domain.RoundResult) error {
+	r.writes = append(r.writes, "results "+results[0].RoundID)
+	return nil
+}
+
+func TestRoundHistoryRunDrainsInOrderOnShutdown(t *testing.T) {
+	repository := &savedRounds{}
+	history := NewRoundHistory(repository)
+
+	history.Record(domain.Round{ID: "r1", Phase: domain.Lobby})
+	history.Record(domain.Round{ID: "r1", Phase: domain.Live})
+	history.RecordResults(nil)
+	history.RecordResults([]domain.RoundResult{{RoundID: "r1"}})
+	history.Record(domain.Round{ID: "r1", Phase: domain.Closed})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	history.Run(ctx)
+
+	want := []string{"round r1 lobby", "round r1 live", "results r1", "round r1 closed"}
+	if !reflect.DeepEqual(repository.writes, want) {
+		t.Fatalf("writes = %v, want %v", repository.writes, want)
+	}
+}
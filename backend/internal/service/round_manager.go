@@ -5,52 +5,76 @@ import (
 	cryptorand "crypto/rand"
 	"fmt"
 	"log"
-	"math/rand/v2"
 	"sync"
 	"time"
+	"tradeoff/backend/internal/config"
 	"tradeoff/backend/internal/domain"
 )
 
 type RoundManager struct {
-	mu             sync.RWMutex
-	hub            *Hub
-	marketService  *MarketService
-	playerService  *PlayerService
-	phase          domain.Phase
-	phaseEndTime   time.Time
-	roundID        string
-	chartDataChan  chan []domain.PriceData
-	chartData      []domain.PriceData
-	hourlyDataChan chan []domain.PriceData
-	hourlyData     []domain.PriceData
-	ctx            context.Context
-	cancel         context.CancelFunc
+	mu sync.RWMutex
+	// tradeMu keeps trades and bar processing apart: a bar is applied under
+	// the write lock and trades fill under the read lock.
+	tradeMu       sync.RWMutex
+	hub           *Hub
+	marketService *MarketService
+	playerService *PlayerService
+	orderService  *OrderService
+	phase         domain.Phase
+	phaseEndTime  time.Time
+	roundID       string
+	preparer      *RoundPreparer
+	quoteStream   QuoteStream
+	history       *RoundHistory
+	record        domain.Round
+	roundType     domain.RoundType
+	chartData     []domain.PriceData
+	replayData    []domain.PriceData
+	tick          uint64
+	format        roundFormat
+	disguise      priceDisguise
+	config        *config.Config
+	asset         domain.Asset
+	synthetic     bool
+	seed          uint64
+	ctx           context.Context
+	cancel        context.CancelFunc
+	// lastBarTime is the time of the last live bar, which live rounds use
+	// to tell how much game time each bar covers.
+	lastBarTime int64
 }
 
 const (
-	LobbyDuration     = 15 * time.Second
-	LiveDuration      = 1 * time.Minute
-	CooldownDuration  = 10 * time.Second
-	HourlyDataForDays = 10
-	RoundDuration     = LobbyDuration + LiveDuration + CooldownDuration
-	Ticker            = "X:BTCUSD"
-	StartingBalance   = 100.0
+	LobbyDuration    = 15 * time.Second
+	LiveDuration     = 1 * time.Minute
+	CooldownDuration = 10 * time.Second
+	RoundDuration    = LobbyDuration + LiveDuration + CooldownDuration
+	StartingBalance  = 100.0
 )
 
-func NewRoundManager(ctx context.Context, hub *Hub, marketService *MarketService, playerService *PlayerService) *RoundManager {
+func NewRoundManager(ctx context.Context, hub *Hub, marketService *MarketService, playerService *PlayerService, orderService *OrderService, quoteStream QuoteStream, history *RoundHistory, config *config.Config) (*RoundManager, error) {
 	rmCtx, cancel := context.WithCancel(ctx)
+	preparer, err := NewRoundPreparer(rmCtx, marketService, config, quoteStream != nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
 	rm := &RoundManager{
-		hub:            hub,
-		marketService:  marketService,
-		playerService:  playerService,
-		chartDataChan:  make(chan []domain.PriceData),
-		hourlyDataChan: make(chan []domain.PriceData),
-		ctx:            rmCtx,
-		cancel:         cancel,
+		hub:           hub,
+		marketService: marketService,
+		playerService: playerService,
+		orderService:  orderService,
+		preparer:      preparer,
+		quoteStream:   quoteStream,
+		history:       history,
+		roundType:     domain.RoundTypeReplay,
+		disguise:      identityDisguise,
+		config:        config,
+		ctx:           rmCtx,
+		cancel:        cancel,
 	}
 
-	rm.transitionToLobby()
-	return rm
+	return rm, nil
 }
 
 // Shutdown gracefully stops the round manager
@@ -60,6 +84,9 @@ func (r *RoundManager) Shutdown() {
 }
 
 func (r *RoundManager) Run() {
+	go r.preparer.Run()
+	r.transitionToLobby()
+
 	timer := time.NewTicker(1 * time.Second)
 	defer timer.Stop()
 
@@ -95,32 +122,43 @@ func (r *RoundManager) GetGameState(playerId string, username string) (GameState
 
 	r.mu.RLock()
 	roundID := r.roundID
-	chartData := r.chartData
+	chartData := r.disguise.series(r.chartData)
 	phase := r.phase
 	phaseEndTime := r.phaseEndTime
+	reveal := r.revealUnsafe()
+	format := r.format
+	roundType := r.roundType
+	tick := r.tick
+	limits := newTradeLimits(r.format.limits)
 	r.mu.RUnlock()
 
-	session := r.playerService.GetPlayerSessionOrCreate(playerId, &username)
-	totalPnl, activePnl, balance, activePnlPercentage := r.playerService.GetPlayerStat(playerId)
+	r.playerService.GetPlayerSessionOrCreate(playerId, &username)
+	playerState := r.playerService.GetPlayerState(playerId)
+	totalPnl, activePnl, _, activePnlPercentage := r.playerService.GetPlayerStat(playerId)
 	longPositions, shortPositions := r.playerService.GetPositionsCount()
 
 	return GameStatePayload{
-		RoundID:   roundID,
-		ChartData: chartData,
+		RoundID:          roundID,
+		RoundType:        roundType,
+		ChartData:        chartData,
+		CandleTimeframe:  format.candles.String(),
+		ReplayResolution: format.replay.String(),
+		MaxLeverage:      r.MaxLeverage(),
+		Tick:             tick,
+		TradeLimits:      limits,
+		TradesRemaining:  r.playerService.TradesRemaining(playerId),
 		PhaseChangePayload: PhaseChangePayload{
 			Phase:   phase,
 			EndTime: phaseEndTime,
+			Reveal:  reveal,
 		},
 		CountUpdatePayload: CountUpdatePayload{
 			TotalPlayers:   r.playerService.GetPlayerCount(),
 			LongPositions:  longPositions,
 			ShortPositions: shortPositions,
 		},
-		BasePlayerState: domain.BasePlayerState{
-			Balance:         balance,
-			ActivePosition:  session.ActivePosition,
-			ClosedPositions: session.ClosedPositions,
-		},
+		BasePlayerState: playerState,
+		PendingOrders:   r.orderService.GetPendingOrders(playerId),
 
 		TotalPnl:            totalPnl,
 		ActivePnl:           activePnl,
@@ -135,8 +173,9 @@ func (r *RoundManager) transitionToLive() {
 	log.Println("--- Transitioning to Live Phase ---")
 	r.phase = domain.Live
 	r.phaseEndTime = time.Now().Add(LiveDuration)
+	r.lastBarTime = 0
 
-	if len(r.chartData) == 0 || len(r.hourlyData) == 0 {
+	if len(r.chartData) == 0 || (r.roundType == domain.RoundTypeReplay && len(r.replayData) == 0) {
 		log.Println("Failed to load chart data for live phase, transitioning to cooldown")
 		r.transitionToCooldownUnsafe()
 		return
@@ -148,10 +187,21 @@ func (r *RoundManager) transitionToLive() {
 	}
 	r.broadcastPhaseUpdate(data)
 
+	now := time.Now()
+	r.record.Phase = r.phase
+	r.record.LiveAt = &now
+	if r.roundType == domain.RoundTypeLive {
+		r.record.From = now
+	}
+	r.history.Record(r.record)
+
 	go r.runLivePhase()
 }
 
 func (r *RoundManager) transitionToCooldown() {
+	// No trade may land between the last bar and settlement.
+	r.tradeMu.Lock()
+	defer r.tradeMu.Unlock()
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.transitionToCooldownUnsafe()
@@ -161,22 +211,109 @@ func (r *RoundManager) transitionToCooldownUnsafe() {
 	log.Println("--- Transitioning to Cooldown Phase ---")
 	r.phase = domain.Closed
 	r.phaseEndTime = time.Now().Add(CooldownDuration)
+	log.Printf("Round %s traded %s (%s)", r.roundID, r.asset.Name, r.asset.Ticker)
+
+	if expired := r.orderService.ExpireAll(); len(expired) > 0 {
+		log.Printf("Expired %d pending orders", len(expired))
+		r.sendOrderUpdates(expired)
+	}
 
 	data := PhaseChangePayload{
 		Phase:   r.phase,
 		EndTime: r.phaseEndTime,
+		Reveal:  r.revealUnsafe(),
 	}
 	r.broadcastPhaseUpdate(data)
+
+	settlements := r.settleRoundUnsafe()
+
+	now := time.Now()
+	r.record.Phase = r.phase
+	r.record.ClosedAt = &now
+	if r.roundType == domain.RoundTypeLive {
+		r.record.To = now
+	}
+	r.record.FinalPrice = r.currentPriceUnsafe()
+	r.record.PriceScale = r.disguise.priceScale
+	// Only players who traded have a result worth keeping.
+	var results []domain.RoundResult
+	for _, settlement := range settlements {
+		if settlement.Result.Trades > 0 {
+			results = append(results, domain.RoundResult{
+				RoundID:      r.roundID,
+				PlayerResult: settlement.Result,
+				Players:      len(settlements),
+				SettledAt:    now,
+			})
+		}
+	}
+	r.record.Participants = len(results)
+	r.history.Record(r.record)
+	r.history.RecordResults(results)
+}
+
+// settleRoundUnsafe closes every open position at the final price, sends each
+// player the round's result and returns the settlements. Must be called with
+// r.mu held.
+func (r *RoundManager) settleRoundUnsafe() []Settlement {
+	price := r.currentPriceUnsafe()
+	if price == 0 {
+		log.Println("Warning: Current price is 0, skipping round settlement")
+		return nil
+	}
+
+	settlements := r.playerService.SettleRound(price)
+	if len(settlements) == 0 {
+		return nil
+	}
+	log.Printf("Settled round %s for %d players at %.2f", r.roundID, len(settlements), price)
+
+	podium := make([]domain.PlayerResult, 0, PodiumSize)
+	for _, settlement := range settlements[:min(PodiumSize, len(settlements))] {
+		podium = append(podium, settlement.Result)
+	}
+	for _, settlement := range settlements {
+		client, exists := r.hub.Clients[settlement.Result.PlayerId]
+		if !exists {
+			continue
+		}
+		r.hub.SendDirect <- DirectMessage{
+			Client: client,
+			Message: WsMessage{
+				Type: WsMsgTypeRoundResult,
+				Data: RoundResultPayload{
+					RoundID: r.roundID,
+					Podium:  podium,
+					Players: len(settlements),
+					Result:  settlement.Result,
+					Settled: settlement.Positions,
+				},
+			},
+		}
+	}
+
+	r.broadcastCountUpdate()
+	r.hub.Broadcast <- WsMessage{
+		Type: WsMsgTypeLeaderboardUpdate,
+		Data: r.playerService.GetLeaderboard(),
+	}
+	return settlements
 }
 
 func (r *RoundManager) transitionToLobby() {
 	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	log.Println("--- Transitioning to Lobby Phase ---")
 	r.phase = domain.Lobby
 	r.phaseEndTime = time.Now().Add(LobbyDuration)
 	r.roundID = generateUUID()
+	r.record = domain.Round{ID: r.roundID, Phase: r.phase, LobbyAt: time.Now()}
+	r.chartData = []domain.PriceData{}
+	r.replayData = []domain.PriceData{}
+	r.tick = 0
+	r.disguise = identityDisguise
+
+	// Orders placed since the last Live phase do not carry into the new round.
+	r.orderService.ExpireAll()
 
 	// Reset all existing players for the new round
 	playerCount := r.playerService.GetPlayerCount()
@@ -185,44 +322,48 @@ func (r *RoundManager) transitionToLobby() {
 		log.Printf("Reset %d players for new round %s", playerCount, r.roundID)
 	}
 
-	r.loadMarketData()
-
 	data := PhaseChangePayload{
 		Phase:   r.phase,
 		EndTime: r.phaseEndTime,
 	}
 	r.broadcastPhaseUpdate(data)
+	r.mu.Unlock()
 
-	// Wait for market data with timeout
-	select {
-	case r.chartData = <-r.chartDataChan:
-		log.Printf("Loaded %d daily chart data points", len(r.chartData))
-	case <-r.ctx.Done():
-		log.Println("Context cancelled while loading chart data")
+	// Rounds are normally prefetched already; if not, wait for one without
+	// holding r.mu so game state stays readable in the meantime.
+	round := r.nextPreparedRound()
+	if round == nil {
 		return
-	case <-time.After(30 * time.Second):
-		log.Println("Timeout loading chart data")
-		r.chartData = []domain.PriceData{}
 	}
 
-	select {
-	case r.hourlyData = <-r.hourlyDataChan:
-		log.Printf("Loaded %d hourly data points", len(r.hourlyData))
-	case <-r.ctx.Done():
-		log.Println("Context cancelled while loading hourly data")
-		return
-	case <-time.After(30 * time.Second):
-		log.Println("Timeout loading hourly data")
-		r.hourlyData = []domain.PriceData{}
-	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	log.Printf("Loaded %d daily chart data and %d hourly data", len(r.chartData), len(r.hourlyData))
+	r.roundType = round.roundType
+	r.asset = round.asset
+	r.synthetic = round.synthetic
+	r.seed = round.seed
+	r.format = round.format
+	r.playerService.SetRound(r.roundID)
+	r.playerService.SetTradeLimits(r.format.limits)
+	r.disguise = round.disguise
+	r.chartData = round.chartData
+	r.replayData = round.replayData
+	r.recordRoundUnsafe()
+	log.Printf("Round %s (%s) will trade %s with %d %s candles and %d %s replay bars", r.roundID, r.roundType, r.asset.Ticker, len(r.chartData), r.format.candles, len(r.replayData), r.format.replay)
 
 	longPositions, shortPositions := r.playerService.GetPositionsCount()
+	limits := newTradeLimits(r.format.limits)
 
 	gameState := GameStatePayload{
 		RoundID:            r.roundID,
-		ChartData:          r.chartData,
+		RoundType:          r.roundType,
+		ChartData:          r.disguise.series(r.chartData),
+		CandleTimeframe:    r.format.candles.String(),
+		ReplayResolution:   r.format.replay.String(),
+		MaxLeverage:        r.MaxLeverage(),
+		TradeLimits:        limits,
+		TradesRemaining:    limits.allowance(),
 		PhaseChangePayload: data,
 		CountUpdatePayload: CountUpdatePayload{
 			TotalPlayers:   r.playerService.GetPlayerCount(),
@@ -231,9 +372,10 @@ func (r *RoundManager) transitionToLobby() {
 		},
 		BasePlayerState: domain.BasePlayerState{
 			Balance:         StartingBalance,
-			ActivePosition:  nil,
+			ActivePositions: []*domain.Position{},
 			ClosedPositions: []domain.ClosedPosition{},
 		},
+		PendingOrders: []domain.Order{},
 
 		TotalPnl:            0,
 		ActivePnl:           0,
@@ -246,93 +388,120 @@ func (r *RoundManager) transitionToLobby() {
 	}
 }
 
-func (r *RoundManager) broadcastPhaseUpdate(data PhaseChangePayload) {
-	msg := WsMessage{
-		Type: WsMsgTypePhaseUpdate,
-		Data: data,
+// nextPreparedRound takes the next round from the prefetch queue, waiting at
+// most the length of the lobby for one to become ready.
+func (r *RoundManager) nextPreparedRound() *preparedRound {
+	select {
+	case round := <-r.preparer.Ready():
+		return round
+	default:
 	}
-	r.hub.Broadcast <- msg
-}
 
-func generateUUID() string {
-	b := make([]byte, 16)
-	_, err := cryptorand.Read(b)
-	if err != nil {
-		log.Printf("Error generating UUID: %v", err)
-		return ""
+	log.Println("No prepared round ready, waiting for market data")
+	select {
+	case round := <-r.preparer.Ready():
+		return round
+	case <-r.ctx.Done():
+		log.Println("Context cancelled while waiting for a prepared round")
+		return nil
+	case <-time.After(LobbyDuration):
+		log.Println("Timeout waiting for a prepared round")
+		return nil
 	}
-	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
 }
 
-func truncateToDate(t time.Time) time.Time {
-	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+// MaxLeverage returns the leverage cap, which is the same in every round so
+// that it does not hint at the asset before the reveal.
+func (r *RoundManager) MaxLeverage() float64 {
+	if r.config.Trading.MaxLeverage > 0 {
+		return r.config.Trading.MaxLeverage
+	}
+	return DefaultMaxLeverage
 }
 
-func (r *RoundManager) loadMarketData() {
-	randomDecrease := -3 - int(rand.Float64()*10)
-	go r.loadDailyChartData(randomDecrease)
-	go r.loadHourlyChartData(randomDecrease)
+// recordRoundUnsafe fills the round's record in from the round just loaded
+// and saves it. Must be called with r.mu held.
+func (r *RoundManager) recordRoundUnsafe() {
+	r.record.Type = r.roundType
+	r.record.Asset = r.asset
+	r.record.Synthetic = r.synthetic
+	r.record.Seed = r.seed
+	r.record.CandleTimeframe = r.format.candles.String()
+	r.record.ReplayResolution = r.format.replay.String()
+	if len(r.replayData) > 0 {
+		r.record.From = time.Unix(r.replayData[0].Time, 0).UTC()
+		r.record.To = time.Unix(r.replayData[len(r.replayData)-1].Time, 0).UTC()
+	}
+	r.history.Record(r.record)
 }
 
-func (r *RoundManager) loadDailyChartData(randomDecrease int) {
-	from := truncateToDate(time.Now().UTC().AddDate(-2, 0, 0))
-	to := truncateToDate(time.Now().UTC().AddDate(0, randomDecrease, 0))
-	log.Printf("Loading daily chart data from %s to %s", from, to)
-	limit := int(to.Sub(from).Hours() / 24)
+// barDurationUnsafe returns the game time bar covers: the replay resolution,
+// or for live bars the time since the previous one. Must be called with r.mu
+// held.
+func (r *RoundManager) barDurationUnsafe(bar domain.PriceData) time.Duration {
+	duration := r.format.replay.Duration()
+	if duration == 0 && r.lastBarTime > 0 {
+		duration = time.Duration(bar.Time-r.lastBarTime) * time.Second
+	}
+	r.lastBarTime = bar.Time
+	return max(duration, 0)
+}
 
-	chartData, err := r.marketService.LoadPriceData(r.ctx, Ticker, from, to, "day", &limit)
-	if err != nil {
-		log.Printf("Error loading daily price data: %v", err)
-		r.chartDataChan <- nil
-		return
+// revealUnsafe returns the round's reveal once it is in cooldown, nil before.
+// Must be called with r.mu held.
+func (r *RoundManager) revealUnsafe() *RoundReveal {
+	if r.phase != domain.Closed {
+		return nil
+	}
+	reveal := &RoundReveal{
+		Asset:      r.asset,
+		Synthetic:  r.synthetic,
+		Seed:       r.seed,
+		PriceScale: r.disguise.priceScale,
+		TimeOffset: r.disguise.timeOffset,
 	}
-	r.chartDataChan <- chartData
+	if len(r.replayData) > 0 {
+		reveal.From = time.Unix(r.replayData[0].Time, 0).UTC()
+		reveal.To = time.Unix(r.replayData[len(r.replayData)-1].Time, 0).UTC()
+	}
+	return reveal
 }
 
-func (r *RoundManager) loadHourlyChartData(randomDecrease int) {
-	from := truncateToDate(time.Now().UTC().AddDate(0, randomDecrease, 0))
-	to := truncateToDate(from.AddDate(0, 0, HourlyDataForDays))
-	log.Printf("Loading hourly chart data from %s to %s", from, to)
+func (r *RoundManager) broadcastPhaseUpdate(data PhaseChangePayload) {
+	msg := WsMessage{
+		Type: WsMsgTypePhaseUpdate,
+		Data: data,
+	}
+	r.hub.Broadcast <- msg
+}
 
-	limit := HourlyDataForDays * 24 * 60
-	hourlyData, err := r.marketService.LoadPriceData(r.ctx, Ticker, from, to, "hour", &limit)
+func generateUUID() string {
+	b := make([]byte, 16)
+	_, err := cryptorand.Read(b)
 	if err != nil {
-		log.Printf("Error loading hourly price data: %v", err)
-		r.hourlyDataChan <- nil
-		return
+		log.Printf("Error generating UUID: %v", err)
+		return ""
 	}
-	r.hourlyDataChan <- hourlyData
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
 }
 
+// sendPriceUpdate folds a replayed or streamed bar into the chart at the round's candle
+// timeframe and broadcasts the affected candle.
 func (r *RoundManager) sendPriceUpdate(priceData domain.PriceData) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	updateLast := false
-	if len(r.chartData) == 0 {
-		r.chartData = append(r.chartData, priceData)
-	} else {
-		lastChartData := &r.chartData[len(r.chartData)-1]
-		lastDataTime := time.Unix(lastChartData.Time, 0)
-		priceDataTime := time.Unix(priceData.Time, 0)
-
-		if lastDataTime.Day() != priceDataTime.Day() {
-			r.chartData = append(r.chartData, priceData)
-		} else {
-			updateLast = true
-			lastChartData.High = max(lastChartData.High, priceData.High)
-			lastChartData.Low = min(lastChartData.Low, priceData.Low)
-			lastChartData.Close = priceData.Close
-			lastChartData.Volume += priceData.Volume
-		}
-	}
+	var updateLast bool
+	r.chartData, updateLast = aggregateCandle(r.chartData, priceData, r.format.candles)
+	r.tick++
 
-	lastChartData := r.chartData[len(r.chartData)-1]
+	lastChartData := r.disguise.bar(r.chartData[len(r.chartData)-1])
 	msg := WsMessage{
 		Type: WsMsgTypePriceUpdate,
 		Data: PriceUpdate{
 			PriceData:  lastChartData,
 			UpdateLast: updateLast,
+			Tick:       r.tick,
 		},
 	}
 	r.hub.Broadcast <- msg
@@ -342,15 +511,23 @@ func (r *RoundManager) runLivePhase() {
 	log.Println("--- Running Live Phase ---")
 
 	r.mu.RLock()
-	hourlyData := r.hourlyData
+	roundType := r.roundType
+	asset := r.asset
+	phaseEndTime := r.phaseEndTime
+	replayData := r.replayData
 	r.mu.RUnlock()
 
-	if len(hourlyData) == 0 {
-		log.Println("No hourly data to broadcast")
+	if roundType == domain.RoundTypeLive {
+		r.streamLivePhase(asset.Ticker, phaseEndTime)
 		return
 	}
 
-	livePhaseTick := LiveDuration / time.Duration(len(hourlyData))
+	if len(replayData) == 0 {
+		log.Println("No replay data to broadcast")
+		return
+	}
+
+	livePhaseTick := LiveDuration / time.Duration(len(replayData))
 	log.Printf("Live phase tick duration: %s", livePhaseTick)
 	ticker := time.NewTicker(livePhaseTick)
 	defer ticker.Stop()
@@ -366,19 +543,128 @@ func (r *RoundManager) runLivePhase() {
 			currentPhase := r.phase
 			r.mu.RUnlock()
 
-			if i >= len(hourlyData) || currentPhase != domain.Live {
+			if i >= len(replayData) || currentPhase != domain.Live {
 				log.Println("--- Live Phase Finished ---")
 				return
 			}
 
-			priceData := hourlyData[i]
-			r.sendPriceUpdate(priceData)
-			r.sendPnlUpdate()
+			r.processBar(replayData[i])
 			i++
 		}
 	}
 }
 
+// streamLivePhase feeds bars from the quote stream into the chart until the
+// Live phase ends or the feed drops.
+func (r *RoundManager) streamLivePhase(ticker string, phaseEndTime time.Time) {
+	ctx, cancel := context.WithDeadline(r.ctx, phaseEndTime)
+	defer cancel()
+
+	bars, err := r.quoteStream.Subscribe(ctx, ticker)
+	if err != nil {
+		log.Printf("Error subscribing to quotes for %s: %v", ticker, err)
+		return
+	}
+	log.Printf("Streaming live quotes for %s", ticker)
+
+	for bar := range bars {
+		r.mu.RLock()
+		currentPhase := r.phase
+		r.mu.RUnlock()
+
+		if currentPhase != domain.Live {
+			break
+		}
+		r.processBar(bar)
+	}
+	log.Println("--- Live Phase Finished ---")
+}
+
+// processBar applies one live bar: it updates the chart, fills any exit
+// levels and then any entry orders the bar reached, charges a bar of funding
+// and marks the open positions to market. Levels are set in displayed prices, so they are
+// checked against the disguised bar. Trades wait until the bar is applied.
+func (r *RoundManager) processBar(bar domain.PriceData) {
+	r.tradeMu.Lock()
+	defer r.tradeMu.Unlock()
+
+	r.sendPriceUpdate(bar)
+
+	r.mu.Lock()
+	disguised := r.disguise.bar(bar)
+	elapsed := r.barDurationUnsafe(bar)
+	r.mu.Unlock()
+
+	r.evaluateTriggers(disguised)
+	r.evaluateOrders(disguised)
+	r.playerService.ApplyFunding(disguised.Close, elapsed)
+	r.sendPnlUpdate()
+}
+
+// evaluateOrders fills pending entry orders the bar reached.
+func (r *RoundManager) evaluateOrders(bar domain.PriceData) {
+	filled := r.orderService.EvaluateOrders(bar)
+	if len(filled) == 0 {
+		return
+	}
+	for _, update := range filled {
+		log.Printf("Filled %s %s order %s of player %s at %.2f", update.Order.Type, update.Order.Side, update.Order.ID, update.PlayerID, update.Order.Price)
+	}
+	r.sendOrderUpdates(filled)
+	r.broadcastCountUpdate()
+}
+
+// sendOrderUpdates tells each player about their orders leaving the book.
+func (r *RoundManager) sendOrderUpdates(updates []OrderUpdate) {
+	for _, update := range updates {
+		client, exists := r.hub.Clients[update.PlayerID]
+		if !exists {
+			continue
+		}
+		_, _, balance, _ := r.playerService.GetPlayerStat(update.PlayerID)
+		r.hub.SendDirect <- DirectMessage{
+			Client: client,
+			Message: WsMessage{
+				Type: WsMsgTypeOrderUpdate,
+				Data: OrderUpdatePayload{
+					Order:    update.Order,
+					Position: update.Position,
+					Balance:  balance,
+				},
+			},
+		}
+	}
+}
+
+// evaluateTriggers closes positions whose exit levels the bar reached.
+func (r *RoundManager) evaluateTriggers(bar domain.PriceData) {
+	fills := r.playerService.EvaluateTriggers(bar)
+	if len(fills) == 0 {
+		return
+	}
+
+	for _, fill := range fills {
+		log.Printf("Filled %s of player %s at %.2f", fill.Position.CloseReason, fill.PlayerID, fill.Position.ExitPrice)
+
+		client, exists := r.hub.Clients[fill.PlayerID]
+		if !exists {
+			continue
+		}
+		_, _, balance, _ := r.playerService.GetPlayerStat(fill.PlayerID)
+		r.hub.SendDirect <- DirectMessage{
+			Client: client,
+			Message: WsMessage{
+				Type: WsMsgTypeOrderFilled,
+				Data: OrderFilledPayload{
+					Position: fill.Position,
+					Balance:  balance,
+				},
+			},
+		}
+	}
+	r.broadcastCountUpdate()
+}
+
 func (r *RoundManager) sendPnlUpdate() {
 	if len(r.chartData) == 0 {
 		return
@@ -391,12 +677,16 @@ func (r *RoundManager) sendPnlUpdate() {
 	}
 
 	// Update PnL for all players with active positions
-	pnlUpdated := r.playerService.UpdateAllPlayerPnl(currentPrice)
+	pnlUpdated, liquidations := r.playerService.UpdateAllPlayerPnl(currentPrice)
 
 	if !pnlUpdated {
 		return
 	}
 
+	if len(liquidations) > 0 {
+		r.sendLiquidations(liquidations)
+	}
+
 	// Get all sessions and send individual PnL updates
 	sessions := r.playerService.GetAllSessions()
 	for playerID := range sessions {
@@ -409,6 +699,8 @@ func (r *RoundManager) sendPnlUpdate() {
 				Balance:             balance,
 				ActivePnl:           activePnl,
 				ActivePnlPercentage: activePnlPercentage,
+				TrailingStops:       r.playerService.GetTrailingStops(playerID),
+				TradesRemaining:     r.playerService.TradesRemaining(playerID),
 			},
 		}
 
@@ -434,12 +726,54 @@ func (r *RoundManager) sendPnlUpdate() {
 	}
 }
 
+// sendLiquidations notifies each liquidated player and broadcasts the new
+// position counts.
+func (r *RoundManager) sendLiquidations(liquidations []Liquidation) {
+	for _, liquidation := range liquidations {
+		log.Printf("Liquidated %s position of player %s at %.2f", liquidation.Position.Type, liquidation.PlayerID, liquidation.Position.ExitPrice)
+
+		client, exists := r.hub.Clients[liquidation.PlayerID]
+		if !exists {
+			continue
+		}
+		_, _, balance, _ := r.playerService.GetPlayerStat(liquidation.PlayerID)
+		r.hub.SendDirect <- DirectMessage{
+			Client: client,
+			Message: WsMessage{
+				Type: WsMsgTypeLiquidation,
+				Data: LiquidationPayload{
+					Position: liquidation.Position,
+					Balance:  balance,
+				},
+			},
+		}
+	}
+
+	r.broadcastCountUpdate()
+}
+
+func (r *RoundManager) broadcastCountUpdate() {
+	longPositions, shortPositions := r.playerService.GetPositionsCount()
+	r.hub.Broadcast <- WsMessage{
+		Type: WsMsgTypeCountUpdate,
+		Data: CountUpdatePayload{
+			LongPositions:  longPositions,
+			ShortPositions: shortPositions,
+			TotalPlayers:   r.playerService.GetPlayerCount(),
+		},
+	}
+}
+
 func (r *RoundManager) GetCurrentPrice() float64 {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	return r.currentPriceUnsafe()
+}
 
+func (r *RoundManager) currentPriceUnsafe() float64 {
 	if len(r.chartData) == 0 {
 		return 0
 	}
-	return r.chartData[len(r.chartData)-1].Close
+	// Players trade at the disguised price; returns are identical to the real series.
+	return r.disguise.price(r.chartData[len(r.chartData)-1].Close)
 }
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+const (
+	DefaultPrefetchDepth  = 2
+	DefaultReplayBars     = 240
+	DefaultHistoryCandles = 730
+	prepareRetryDelay     = 5 * time.Second
+)
+
+// DefaultRoundFormat replays hourly bars into daily candles.
+var DefaultRoundFormat = roundFormat{
+	replay:  ResolutionHour,
+	candles: ResolutionDay,
+}
+
+// roundFormat is the replay resolution and candle timeframe of a round and
+// the trading limits that apply to it.
+type roundFormat struct {
+	replay  Resolution
+	candles Resolution
+	limits  config.LimitsConfig
+}
+
+// preparedRound holds everything needed to start a round, loaded and validated
+// ahead of time so the lobby can swap it in without waiting on market data.
+type preparedRound struct {
+	roundType  domain.RoundType
+	asset      domain.Asset
+	format     roundFormat
+	disguise   priceDisguise
+	synthetic  bool
+	seed       uint64
+	chartData  []domain.PriceData
+	replayData []domain.PriceData
+}
+
+// RoundPreparer loads upcoming rounds in the background and keeps a bounded
+// queue of ready ones. It blocks once the queue is full, so at most
+// PrefetchDepth rounds are held in memory.
+type RoundPreparer struct {
+	marketService *MarketService
+	config        *config.Config
+	validator     seriesValidator
+	synthetic     SyntheticParams
+	liveEnabled   bool
+	ready         chan *preparedRound
+	// lastGood is the most recent historical round that loaded and validated,
+	// replayed when the market data provider is unavailable.
+	lastGood *preparedRound
+	ctx      context.Context
+}
+
+func NewRoundPreparer(ctx context.Context, marketService *MarketService, config *config.Config, liveEnabled bool) (*RoundPreparer, error) {
+	synthetic, err := NewSyntheticParams(config.Rounds.Synthetic)
+	if err != nil {
+		return nil, err
+	}
+	depth := config.Rounds.PrefetchDepth
+	if depth <= 0 {
+		depth = DefaultPrefetchDepth
+	}
+	return &RoundPreparer{
+		marketService: marketService,
+		config:        config,
+		validator:     newSeriesValidator(config.Rounds.Quality),
+		synthetic:     synthetic,
+		liveEnabled:   liveEnabled,
+		ready:         make(chan *preparedRound, depth),
+		ctx:           ctx,
+	}, nil
+}
+
+// Ready returns the queue of prepared rounds.
+func (p *RoundPreparer) Ready() <-chan *preparedRound {
+	return p.ready
+}
+
+func (p *RoundPreparer) Run() {
+	for {
+		round, err := p.prepare()
+		if err != nil {
+			log.Printf("Error preparing round: %v", err)
+			select {
+			case <-p.ctx.Done():
+				return
+			case <-time.After(prepareRetryDelay):
+			}
+			continue
+		}
+
+		select {
+		case p.ready <- round:
+			log.Printf("Prepared %s %s round with %d %s candles and %d %s replay bars (%d queued)", round.roundType, round.asset.Ticker, len(round.chartData), round.format.candles, len(round.replayData), round.format.replay, len(p.ready))
+		case <-p.ctx.Done():
+			log.Println("RoundPreparer context cancelled, stopping...")
+			return
+		}
+	}
+}
+
+func (p *RoundPreparer) prepare() (*preparedRound, error) {
+	format, err := p.pickFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.liveEnabled && rand.Float64() < p.config.Rounds.Live.Ratio {
+		round, err := p.prepareLive(format)
+		if err == nil {
+			return round, nil
+		}
+		log.Printf("Live round unavailable (%v), preparing a replay round instead", err)
+	}
+
+	var round *preparedRound
+	historical := false
+	if rand.Float64() < p.config.Rounds.SyntheticRatio {
+		round = p.prepareSynthetic(format)
+	} else {
+		round, err = p.prepareHistorical(pickAsset(p.config.Assets), format)
+		if err != nil {
+			// Never leave the lobby without a chart because the provider is down.
+			round = p.fallbackRound(format)
+			log.Printf("Market data unavailable (%v), falling back to a %s round", err, round.asset.Ticker)
+		} else {
+			historical = true
+		}
+	}
+
+	if err := p.validateRound(round); err != nil {
+		return nil, err
+	}
+	if historical {
+		p.lastGood = round.clone()
+	}
+
+	round.disguise = identityDisguise
+	if p.config.Rounds.Disguise {
+		round.disguise = newPriceDisguise(round.replayData[0])
+	}
+	return round, nil
+}
+
+// fallbackRound replays the last good historical round, which gets a fresh
+// disguise, or generates a synthetic one if no historical round loaded yet.
+func (p *RoundPreparer) fallbackRound(format roundFormat) *preparedRound {
+	if p.lastGood != nil {
+		return p.lastGood.clone()
+	}
+	return p.prepareSynthetic(format)
+}
+
+func (r *preparedRound) clone() *preparedRound {
+	clone := *r
+	clone.chartData = append([]domain.PriceData(nil), r.chartData...)
+	clone.replayData = append([]domain.PriceData(nil), r.replayData...)
+	return &clone
+}
+
+// pickFormat draws the replay resolution and candle timeframe for a round
+// from the configured formats.
+func (p *RoundPreparer) pickFormat() (roundFormat, error) {
+	formatConfig, ok := pickWeighted(p.config.Rounds.Formats, func(f config.RoundFormatConfig) float64 { return f.Weight })
+	if !ok {
+		format := DefaultRoundFormat
+		format.limits = p.config.Trading.Limits
+		return format, nil
+	}
+
+	replay, err := ParseResolution(formatConfig.Replay)
+	if err != nil {
+		return roundFormat{}, fmt.Errorf("invalid replay resolution: %w", err)
+	}
+	candles, err := ParseResolution(formatConfig.Candles)
+	if err != nil {
+		return roundFormat{}, fmt.Errorf("invalid candle timeframe: %w", err)
+	}
+	if candles.Duration() < replay.Duration() {
+		return roundFormat{}, fmt.Errorf("candle timeframe %s is shorter than replay resolution %s", candles, replay)
+	}
+	limits := p.config.Trading.Limits
+	if formatConfig.Limits != nil {
+		limits = *formatConfig.Limits
+	}
+	return roundFormat{replay: replay, candles: candles, limits: limits}, nil
+}
+
+func (p *RoundPreparer) replayBars() int {
+	if p.config.Rounds.ReplayBars > 0 {
+		return p.config.Rounds.ReplayBars
+	}
+	return DefaultReplayBars
+}
+
+func (p *RoundPreparer) historyCandles() int {
+	if p.config.Rounds.HistoryCandles > 0 {
+		return p.config.Rounds.HistoryCandles
+	}
+	return DefaultHistoryCandles
+}
+
+// validateRound checks and repairs both series of a round, logging a quality
+// report for each, and rejects the round if either series is unusable. Live
+// rounds only have a chart series.
+func (p *RoundPreparer) validateRound(round *preparedRound) error {
+	chartData, chartReport := p.validator.validate(round.chartData, round.format.candles, round.asset)
+	chartReport.Series = "chart"
+	logQualityReport(chartReport)
+	if !chartReport.Accepted {
+		return fmt.Errorf("rejected chart data for %s: %s", round.asset.Ticker, chartReport.Reason)
+	}
+	if round.roundType == domain.RoundTypeLive {
+		round.chartData = chartData
+		return nil
+	}
+
+	replayData, replayReport := p.validator.validate(round.replayData, round.format.replay, round.asset)
+	replayReport.Series = "replay"
+	logQualityReport(replayReport)
+	if !replayReport.Accepted {
+		return fmt.Errorf("rejected replay data for %s: %s", round.asset.Ticker, replayReport.Reason)
+	}
+
+	minReplayBars := p.config.Rounds.Quality.MinReplayBars
+	if minReplayBars <= 0 {
+		minReplayBars = DefaultMinReplayBars
+	}
+	if len(replayData) < minReplayBars {
+		return fmt.Errorf("rejected replay data for %s: %d bars, need at least %d", round.asset.Ticker, len(replayData), minReplayBars)
+	}
+
+	round.chartData = chartData
+	round.replayData = replayData
+	return nil
+}
+
+// prepareSynthetic generates the round's history and replay series from a
+// seeded stochastic model instead of fetching market data.
+func (p *RoundPreparer) prepareSynthetic(format roundFormat) *preparedRound {
+	seed := p.config.Rounds.Synthetic.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	generator := NewSyntheticGenerator(p.synthetic, seed)
+	log.Printf("Generating synthetic %s data with seed %d", generator.Model(), seed)
+
+	replayStart := format.candles.BucketStart(time.Now())
+	historyCandles := p.historyCandles()
+	historyStart := replayStart.Add(-time.Duration(historyCandles) * format.candles.Duration())
+
+	return &preparedRound{
+		roundType: domain.RoundTypeReplay,
+		asset: domain.Asset{
+			Ticker: "SYNTH",
+			Name:   fmt.Sprintf("Synthetic (%s)", generator.Model()),
+			Class:  domain.AssetClassSynthetic,
+		},
+		format:     format,
+		synthetic:  true,
+		seed:       seed,
+		chartData:  generator.Generate(historyStart, format.candles.Duration(), historyCandles),
+		replayData: generator.Generate(replayStart, format.replay.Duration(), p.replayBars()),
+	}
+}
+
+// prepareHistorical loads a random past window for asset. The replay starts on
+// a candle boundary and the chart history ends just before it, so the first
+// replayed bar always opens a new candle.
+func (p *RoundPreparer) prepareHistorical(asset domain.Asset, format roundFormat) (*preparedRound, error) {
+	randomDecrease := -3 - int(rand.Float64()*10)
+	replayStart := format.candles.BucketStart(time.Now().AddDate(0, randomDecrease, 0))
+	replayEnd := replayStart.Add(time.Duration(p.replayBars()) * format.replay.Duration())
+	historyStart := replayStart.Add(-time.Duration(p.historyCandles()) * format.candles.Duration())
+
+	log.Printf("Loading %s chart data for %s from %s to %s", format.candles, asset.Ticker, historyStart, replayStart)
+	chartQuery := format.candles.query(asset.Ticker, historyStart, replayStart.Add(-time.Second))
+	chartData, err := p.marketService.LoadPriceData(p.ctx, chartQuery)
+	if err != nil {
+		return nil, fmt.Errorf("loading chart price data: %w", err)
+	}
+
+	log.Printf("Loading %s replay data for %s from %s to %s", format.replay, asset.Ticker, replayStart, replayEnd)
+	replayQuery := format.replay.query(asset.Ticker, replayStart, replayEnd.Add(-time.Second))
+	replayData, err := p.marketService.LoadPriceData(p.ctx, replayQuery)
+	if err != nil {
+		return nil, fmt.Errorf("loading replay price data: %w", err)
+	}
+
+	return &preparedRound{
+		roundType:  domain.RoundTypeReplay,
+		asset:      asset,
+		format:     format,
+		chartData:  chartData,
+		replayData: replayData,
+	}, nil
+}
+
+// prepareLive loads the recent chart history of a crypto asset for a round
+// that streams the current market during its Live phase. Only crypto trades
+// around the clock, so other assets could be closed when the round starts.
+// Live rounds have no replay series and are never disguised.
+func (p *RoundPreparer) prepareLive(format roundFormat) (*preparedRound, error) {
+	var assets []config.AssetConfig
+	for _, asset := range p.config.Assets {
+		if domain.AssetClass(asset.Class) == domain.AssetClassCrypto {
+			assets = append(assets, asset)
+		}
+	}
+	if len(assets) == 0 {
+		return nil, fmt.Errorf("no crypto assets configured for live rounds")
+	}
+	asset := pickAsset(assets)
+	// Live bars arrive at the feed's own cadence.
+	format.replay = Resolution{}
+
+	now := time.Now()
+	historyStart := format.candles.BucketStart(now).Add(-time.Duration(p.historyCandles()) * format.candles.Duration())
+
+	log.Printf("Loading %s chart data for live %s round from %s", format.candles, asset.Ticker, historyStart)
+	chartData, err := p.marketService.LoadPriceData(p.ctx, format.candles.query(asset.Ticker, historyStart, now))
+	if err != nil {
+		return nil, fmt.Errorf("loading chart price data: %w", err)
+	}
+
+	round := &preparedRound{
+		roundType: domain.RoundTypeLive,
+		asset:     asset,
+		format:    format,
+		disguise:  identityDisguise,
+		chartData: chartData,
+	}
+	if err := p.validateRound(round); err != nil {
+		return nil, err
+	}
+	return round, nil
+}
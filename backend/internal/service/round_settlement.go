@@ -0,0 +1,63 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"tradeoff/backend/internal/domain"
+)
+
+// PodiumSize is the number of top-ranked players sent to everyone in
+// 'round_result'.
+const PodiumSize = 3
+
+// Settlement is a player's final result for a round and the positions that
+// were closed to settle it.
+type Settlement struct {
+	Result    domain.PlayerResult
+	Positions []domain.ClosedPosition
+}
+
+// SettleRound closes every open position at price and ranks all players by
+// their final balance. Settlements are returned in rank order.
+func (s *PlayerService) SettleRound(price float64) []Settlement {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settlements := make([]Settlement, 0, len(s.playerSessions))
+	for _, session := range s.playerSessions {
+		settled := []domain.ClosedPosition{}
+		for _, position := range slices.Clone(session.ActivePositions) {
+			settled = append(settled, s.closePositionUnsafe(session, position, price, domain.CloseReasonSettlement))
+		}
+
+		pnl := session.Balance - StartingBalance
+		settlements = append(settlements, Settlement{
+			Result: domain.PlayerResult{
+				PlayerId:         session.PlayerId,
+				Username:         session.Username,
+				Balance:          session.Balance,
+				Pnl:              pnl,
+				ReturnPercentage: pnl / StartingBalance * 100,
+				Trades:           len(session.ClosedPositions),
+			},
+			Positions: settled,
+		})
+	}
+
+	slices.SortFunc(settlements, func(a, b Settlement) int {
+		if a.Result.Balance != b.Result.Balance {
+			if a.Result.Balance > b.Result.Balance {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Result.Username, b.Result.Username)
+	})
+	for i := range settlements {
+		settlements[i].Result.Rank = i + 1
+		if i > 0 && settlements[i].Result.Balance == settlements[i-1].Result.Balance {
+			settlements[i].Result.Rank = settlements[i-1].Result.Rank
+		}
+	}
+	return settlements
+}
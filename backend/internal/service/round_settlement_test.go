@@ -0,0 +1,64 @@
+package service
+
+import (
+	"testing"
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+func TestSettleRound(t *testing.T) {
+	s := NewPlayerService(config.TradingConfig{}, NewTradeHistory(nil))
+	for _, name := range []string{"dave", "bob", "carol", "alice"} {
+		s.GetPlayerSessionOrCreate(name, &name)
+	}
+	s.GetPlayerSessionOrCreate("alice", nil).Balance = 110
+	s.GetPlayerSessionOrCreate("bob", nil).Balance = 110
+	if _, err := s.CreatePosition("carol", domain.PositionTypeLong, 100, PositionSize{Amount: 50, Leverage: 1}, PositionExits{}, 1); err != nil {
+		t.Fatalf("CreatePosition: %v", err)
+	}
+
+	settlements := s.SettleRound(90)
+
+	want := []struct {
+		player  string
+		rank    int
+		balance float64
+		trades  int
+		settled int
+	}{
+		{player: "alice", rank: 1, balance: 110},
+		{player: "bob", rank: 1, balance: 110},
+		{player: "dave", rank: 3, balance: 100},
+		{player: "carol", rank: 4, balance: 95, trades: 1, settled: 1},
+	}
+	if len(settlements) != len(want) {
+		t.Fatalf("got %d settlements, want %d", len(settlements), len(want))
+	}
+	for i, w := range want {
+		result := settlements[i].Result
+		if result.PlayerId != w.player || result.Rank != w.rank || result.Balance != w.balance || result.Trades != w.trades {
+			t.Errorf("settlement %d = %+v, want %s ranked %d with balance %v and %d trades", i, result, w.player, w.rank, w.balance, w.trades)
+		}
+		if result.Pnl != w.balance-StartingBalance || result.ReturnPercentage != (w.balance-StartingBalance)/StartingBalance*100 {
+			t.Errorf("%s pnl = %v (%v%%), want %v", w.player, result.Pnl, result.ReturnPercentage, w.balance-StartingBalance)
+		}
+		if len(settlements[i].Positions) != w.settled {
+			t.Errorf("%s settled %d positions, want %d", w.player, len(settlements[i].Positions), w.settled)
+		}
+	}
+
+	settled := settlements[3].Positions[0]
+	if settled.CloseReason != domain.CloseReasonSettlement || settled.ExitPrice != 90 {
+		t.Errorf("settled position closed by %q at %v, want settlement at 90", settled.CloseReason, settled.ExitPrice)
+	}
+	if active := s.GetPlayerSessionOrCreate("carol", nil).ActivePositions; len(active) != 0 {
+		t.Errorf("carol still holds %d positions after settlement", len(active))
+	}
+}
+
+func TestSettleRoundWithoutPlayers(t *testing.T) {
+	s := NewPlayerService(config.TradingConfig{}, NewTradeHistory(nil))
+	if settlements := s.SettleRound(100); len(settlements) != 0 {
+		t.Fatalf("got %d settlements, want none", len(settlements))
+	}
+}
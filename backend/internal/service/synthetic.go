@@ -0,0 +1,179 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+type SyntheticModel string
+
+const (
+	SyntheticModelGBM             SyntheticModel = "gbm"
+	SyntheticModelJumpDiffusion   SyntheticModel = "jump_diffusion"
+	SyntheticModelRegimeSwitching SyntheticModel = "regime_switching"
+	// SyntheticModelMixed picks one of the concrete models per round.
+	SyntheticModelMixed SyntheticModel = "mixed"
+
+	// syntheticSubSteps is the number of path steps simulated inside each bar
+	// to derive a realistic high and low.
+	syntheticSubSteps = 6
+	yearDuration      = 365 * 24 * time.Hour
+)
+
+var syntheticModels = []SyntheticModel{
+	SyntheticModelGBM,
+	SyntheticModelJumpDiffusion,
+	SyntheticModelRegimeSwitching,
+}
+
+// SyntheticRegime is one state of the regime-switching model. Drift and
+// volatility are annualized; SwitchRate is the expected number of exits from
+// the regime per year.
+type SyntheticRegime struct {
+	Drift      float64
+	Volatility float64
+	SwitchRate float64
+}
+
+// SyntheticParams configures the stochastic price model. Rates and volatilities
+// are annualized so the same parameters work at any bar interval.
+type SyntheticParams struct {
+	Model         SyntheticModel
+	StartPrice    float64
+	Drift         float64
+	Volatility    float64
+	JumpIntensity float64
+	JumpMean      float64
+	JumpStdDev    float64
+	Regimes       []SyntheticRegime
+	BaseVolume    float64
+}
+
+// SyntheticGenerator produces OHLCV series from a seeded stochastic model.
+// The same params and seed always produce the same series.
+type SyntheticGenerator struct {
+	params SyntheticParams
+	rng    *rand.Rand
+	price  float64
+	regime int
+}
+
+func NewSyntheticGenerator(params SyntheticParams, seed uint64) *SyntheticGenerator {
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	if params.Model == SyntheticModelMixed || params.Model == "" {
+		params.Model = syntheticModels[rng.IntN(len(syntheticModels))]
+	}
+	if params.StartPrice <= 0 {
+		params.StartPrice = 100
+	}
+	if params.BaseVolume <= 0 {
+		params.BaseVolume = 1000
+	}
+	return &SyntheticGenerator{
+		params: params,
+		rng:    rng,
+		price:  params.StartPrice,
+	}
+}
+
+// Model returns the concrete model used by the generator.
+func (g *SyntheticGenerator) Model() SyntheticModel {
+	return g.params.Model
+}
+
+// Generate returns count bars of the given interval starting at start. Calls
+// continue the same price path, so a daily history followed by an hourly
+// replay forms one continuous series.
+func (g *SyntheticGenerator) Generate(start time.Time, interval time.Duration, count int) []domain.PriceData {
+	dt := interval.Hours() / yearDuration.Hours() / syntheticSubSteps
+	series := make([]domain.PriceData, 0, count)
+
+	for i := 0; i < count; i++ {
+		open := g.price
+		high := open
+		low := open
+		for step := 0; step < syntheticSubSteps; step++ {
+			g.price *= math.Exp(g.logReturn(dt))
+			high = max(high, g.price)
+			low = min(low, g.price)
+		}
+
+		barReturn := math.Abs(math.Log(g.price / open))
+		volume := g.params.BaseVolume * interval.Hours() * math.Exp(0.5*g.rng.NormFloat64()) * (1 + 50*barReturn)
+
+		series = append(series, domain.PriceData{
+			Time:   start.Add(time.Duration(i) * interval).Unix(),
+			Open:   open,
+			High:   high,
+			Low:    low,
+			Close:  g.price,
+			Volume: volume,
+		})
+	}
+	return series
+}
+
+func (g *SyntheticGenerator) logReturn(dt float64) float64 {
+	drift := g.params.Drift
+	volatility := g.params.Volatility
+
+	if g.params.Model == SyntheticModelRegimeSwitching && len(g.params.Regimes) > 0 {
+		regime := g.params.Regimes[g.regime]
+		if g.rng.Float64() < regime.SwitchRate*dt {
+			g.regime = (g.regime + 1 + g.rng.IntN(max(len(g.params.Regimes)-1, 1))) % len(g.params.Regimes)
+			regime = g.params.Regimes[g.regime]
+		}
+		drift = regime.Drift
+		volatility = regime.Volatility
+	}
+
+	logReturn := (drift-0.5*volatility*volatility)*dt + volatility*math.Sqrt(dt)*g.rng.NormFloat64()
+
+	if g.params.Model == SyntheticModelJumpDiffusion && g.rng.Float64() < g.params.JumpIntensity*dt {
+		logReturn += g.params.JumpMean + g.params.JumpStdDev*g.rng.NormFloat64()
+	}
+	return logReturn
+}
+
+// NewSyntheticParams builds the generator's params from the synthetic config.
+// It rejects an unknown model, and a model that can switch regimes without at
+// least two regimes to switch between.
+func NewSyntheticParams(cfg config.SyntheticConfig) (SyntheticParams, error) {
+	model := SyntheticModel(cfg.Model)
+	switch model {
+	case "", SyntheticModelMixed, SyntheticModelRegimeSwitching:
+		if len(cfg.Regimes) < 2 {
+			return SyntheticParams{}, fmt.Errorf("synthetic model %q requires at least two regimes, got %d", cfg.Model, len(cfg.Regimes))
+		}
+	case SyntheticModelGBM, SyntheticModelJumpDiffusion:
+	default:
+		return SyntheticParams{}, fmt.Errorf("unknown synthetic model %q", cfg.Model)
+	}
+
+	regimes := make([]SyntheticRegime, 0, len(cfg.Regimes))
+	for i, regime := range cfg.Regimes {
+		if regime.Volatility < 0 || regime.SwitchRate <= 0 {
+			return SyntheticParams{}, fmt.Errorf("synthetic regime %d needs a non-negative volatility and a positive switch rate", i)
+		}
+		regimes = append(regimes, SyntheticRegime{
+			Drift:      regime.Drift,
+			Volatility: regime.Volatility,
+			SwitchRate: regime.SwitchRate,
+		})
+	}
+	return SyntheticParams{
+		Model:         model,
+		StartPrice:    cfg.StartPrice,
+		Drift:         cfg.Drift,
+		Volatility:    cfg.Volatility,
+		JumpIntensity: cfg.JumpIntensity,
+		JumpMean:      cfg.JumpMean,
+		JumpStdDev:    cfg.JumpStdDev,
+		Regimes:       regimes,
+		BaseVolume:    cfg.BaseVolume,
+	}, nil
+}
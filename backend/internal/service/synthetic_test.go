@@ -0,0 +1,125 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+var testRegimes = []config.SyntheticRegimeConfig{
+	{Drift: 0.8, Volatility: 0.45, SwitchRate: 6},
+	{Drift: -0.9, Volatility: 0.8, SwitchRate: 8},
+}
+
+func testSyntheticParams(t *testing.T, model SyntheticModel) SyntheticParams {
+	t.Helper()
+	params, err := NewSyntheticParams(config.SyntheticConfig{
+		Model:         string(model),
+		StartPrice:    100,
+		Drift:         0.05,
+		Volatility:    0.6,
+		JumpIntensity: 12,
+		JumpStdDev:    0.05,
+		BaseVolume:    1000,
+		Regimes:       testRegimes,
+	})
+	if err != nil {
+		t.Fatalf("NewSyntheticParams(%q): %v", model, err)
+	}
+	return params
+}
+
+func TestSyntheticGeneratorSeedReproducible(t *testing.T) {
+	start := time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC)
+	models := []SyntheticModel{
+		SyntheticModelGBM,
+		SyntheticModelJumpDiffusion,
+		SyntheticModelRegimeSwitching,
+		SyntheticModelMixed,
+	}
+
+	for _, model := range models {
+		t.Run(string(model), func(t *testing.T) {
+			params := testSyntheticParams(t, model)
+			generate := func(seed uint64) (SyntheticModel, []domain.PriceData) {
+				generator := NewSyntheticGenerator(params, seed)
+				history := generator.Generate(start, 24*time.Hour, 30)
+				replay := generator.Generate(start.Add(30*24*time.Hour), time.Hour, 60)
+				return generator.Model(), append(history, replay...)
+			}
+
+			firstModel, first := generate(42)
+			secondModel, second := generate(42)
+			if firstModel != secondModel || !reflect.DeepEqual(first, second) {
+				t.Fatal("same seed produced different series")
+			}
+			if _, other := generate(43); reflect.DeepEqual(first, other) {
+				t.Fatal("different seeds produced the same series")
+			}
+			if model != SyntheticModelMixed && firstModel != model {
+				t.Fatalf("Model() = %q, want %q", firstModel, model)
+			}
+		})
+	}
+}
+
+func TestSyntheticGeneratorBars(t *testing.T) {
+	start := time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC)
+	generator := NewSyntheticGenerator(testSyntheticParams(t, SyntheticModelJumpDiffusion), 7)
+	history := generator.Generate(start, 24*time.Hour, 20)
+	replay := generator.Generate(start.Add(20*24*time.Hour), time.Hour, 20)
+
+	if history[0].Open != 100 {
+		t.Errorf("first open = %v, want the start price 100", history[0].Open)
+	}
+	if replay[0].Open != history[len(history)-1].Close {
+		t.Errorf("replay opens at %v, want the history's last close %v", replay[0].Open, history[len(history)-1].Close)
+	}
+	for i, bar := range append(history, replay...) {
+		if bar.Low > min(bar.Open, bar.Close) || bar.High < max(bar.Open, bar.Close) || bar.Low <= 0 || bar.Volume <= 0 {
+			t.Fatalf("bar %d is inconsistent: %+v", i, bar)
+		}
+	}
+	if got, want := replay[1].Time-replay[0].Time, int64(time.Hour/time.Second); got != want {
+		t.Errorf("replay bars are %ds apart, want %ds", got, want)
+	}
+}
+
+func TestNewSyntheticParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   string
+		regimes []config.SyntheticRegimeConfig
+		wantErr bool
+	}{
+		{name: "gbm without regimes", model: "gbm"},
+		{name: "jump diffusion without regimes", model: "jump_diffusion"},
+		{name: "regime switching", model: "regime_switching", regimes: testRegimes},
+		{name: "mixed", model: "mixed", regimes: testRegimes},
+		{name: "empty model is mixed", model: "", regimes: testRegimes},
+		{name: "unknown model", model: "jump-diffusion", wantErr: true},
+		{name: "regime switching without regimes", model: "regime_switching", wantErr: true},
+		{name: "regime switching with one regime", model: "regime_switching", regimes: testRegimes[:1], wantErr: true},
+		{name: "mixed without regimes", model: "mixed", wantErr: true},
+		{
+			name:  "regime that never switches",
+			model: "regime_switching",
+			regimes: []config.SyntheticRegimeConfig{
+				{Drift: 0.1, Volatility: 0.3, SwitchRate: 0},
+				{Drift: -0.1, Volatility: 0.3, SwitchRate: 4},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSyntheticParams(config.SyntheticConfig{Model: tt.model, Regimes: tt.regimes})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
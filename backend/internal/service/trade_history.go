@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"log"
+	"slices"
+	"tradeoff/backend/internal/domain"
+)
+
+const (
+	DefaultTradesPageSize = 50
+	MaxTradesPageSize     = 200
+	tradeBatchSize        = 100
+)
+
+// TradeHistory stores every opened and closed position and serves each
+// player's trades. Trades are queued and written in batches by a background
+// writer, so recording one never blocks trading.
+type TradeHistory struct {
+	repository TradeRepository
+	trades     *writeQueue[domain.Trade]
+}
+
+func NewTradeHistory(repository TradeRepository) *TradeHistory {
+	return &TradeHistory{
+		repository: repository,
+		trades:     newWriteQueue[domain.Trade](),
+	}
+}
+
+// Run writes queued trades in batches until ctx is cancelled, then writes the
+// trades still queued and returns.
+func (h *TradeHistory) Run(ctx context.Context) {
+	h.trades.run(ctx, func(trades []domain.Trade) {
+		for batch := range slices.Chunk(trades, tradeBatchSize) {
+			if err := h.repository.SaveTrades(batch); err != nil {
+				log.Printf("Error saving %d trades: %v", len(batch), err)
+			}
+		}
+	})
+}
+
+// Record queues a trade to be saved.
+func (h *TradeHistory) Record(trade domain.Trade) {
+	h.trades.push(trade)
+}
+
+// ListTrades returns a page of the player's trades, most recent first.
+func (h *TradeHistory) ListTrades(playerID string, limit int, offset int) ([]domain.Trade, error) {
+	if limit <= 0 {
+		limit = DefaultTradesPageSize
+	}
+	limit = min(limit, MaxTradesPageSize)
+	return h.repository.ListTrades(playerID, limit, max(offset, 0))
+}
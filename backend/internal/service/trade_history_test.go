@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"tradeoff/backend/internal/domain"
+)
+
+type savedTrades struct {
+	TradeRepository
+	batches [][]domain.Trade
+}
+
+func (r *savedTrades) SaveTrades(trades []domain.Trade) error {
+	r.batches = append(r.batches, trades)
+	return nil
+}
+
+func TestTradeHistoryRunDrainsOnShutdown(t *testing.T) {
+	repository := &savedTrades{}
+	history := NewTradeHistory(repository)
+
+	const recorded = 2*tradeBatchSize + 50
+	for i := range recorded {
+		history.Record(domain.Trade{ID: fmt.Sprint(i)})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		history.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after ctx was cancelled")
+	}
+
+	var saved []domain.Trade
+	for _, batch := range repository.batches {
+		if len(batch) > tradeBatchSize {
+			t.Errorf("saved a batch of %d trades, want at most %d", len(batch), tradeBatchSize)
+		}
+		saved = append(saved, batch...)
+	}
+	if len(saved) != recorded {
+		t.Fatalf("saved %d trades, want all %d recorded", len(saved), recorded)
+	}
+	for i, trade := range saved {
+		if trade.ID != fmt.Sprint(i) {
+			t.Fatalf("trade %d saved as %s, want trades in the order recorded", i, trade.ID)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"fmt"
+	"time"
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+// TradeLimits is the trading rules of a round as sent to clients. Durations
+// are in milliseconds, and zero disables a rule.
+type TradeLimits struct {
+	MaxTrades       int   `json:"maxTrades"`
+	MinHoldMs       int64 `json:"minHoldMs"`
+	OrderCooldownMs int64 `json:"orderCooldownMs"`
+}
+
+func newTradeLimits(limits config.LimitsConfig) TradeLimits {
+	return TradeLimits{
+		MaxTrades:       max(limits.MaxTrades, 0),
+		MinHoldMs:       max(limits.MinHold, 0).Milliseconds(),
+		OrderCooldownMs: max(limits.OrderCooldown, 0).Milliseconds(),
+	}
+}
+
+// allowance is the number of trades each player starts the round with, or
+// nil if trades are not limited.
+func (l TradeLimits) allowance() *int {
+	if l.MaxTrades == 0 {
+		return nil
+	}
+	allowance := l.MaxTrades
+	return &allowance
+}
+
+// SetTradeLimits replaces the trading rules, which apply to the round that
+// is about to start.
+func (s *PlayerService) SetTradeLimits(limits config.LimitsConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = limits
+}
+
+// TradesRemaining returns how many more trades the player may open this
+// round, or nil if the round has no trade limit.
+func (s *PlayerService) TradesRemaining(playerID string) *int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, exists := s.playerSessions[playerID]
+	if !exists {
+		return nil
+	}
+	return s.tradesRemainingUnsafe(session)
+}
+
+func (s *PlayerService) tradesRemainingUnsafe(session *domain.PlayerState) *int {
+	if s.limits.MaxTrades <= 0 {
+		return nil
+	}
+	remaining := max(s.limits.MaxTrades-session.Trades, 0)
+	return &remaining
+}
+
+// checkOrderUnsafe enforces the order cooldown and, for an order that opens
+// a trade, the trade limit. Must be called with s.mu held.
+func (s *PlayerService) checkOrderUnsafe(session *domain.PlayerState, opens bool, now time.Time) error {
+	if s.limits.OrderCooldown > 0 && !session.LastOrderAt.IsZero() {
+		if wait := session.LastOrderAt.Add(s.limits.OrderCooldown).Sub(now); wait > 0 {
+			return fmt.Errorf("%w: wait %s before the next order", ErrOrderCooldown, wait.Round(time.Millisecond))
+		}
+	}
+	if opens && s.limits.MaxTrades > 0 && session.Trades >= s.limits.MaxTrades {
+		return fmt.Errorf("%w: the limit is %d per round", ErrTradeLimitReached, s.limits.MaxTrades)
+	}
+	return nil
+}
+
+// recordOrderUnsafe counts an order checked by checkOrderUnsafe once it has
+// gone through. Must be called with s.mu held.
+func (s *PlayerService) recordOrderUnsafe(session *domain.PlayerState, opens bool, now time.Time) {
+	session.LastOrderAt = now
+	if opens {
+		session.Trades++
+	}
+}
+
+// checkHoldUnsafe enforces the minimum hold time before a position can be
+// closed by hand. Must be called with s.mu held.
+func (s *PlayerService) checkHoldUnsafe(position *domain.Position, now time.Time) error {
+	if s.limits.MinHold <= 0 {
+		return nil
+	}
+	if wait := position.EntryTime.Add(s.limits.MinHold).Sub(now); wait > 0 {
+		return fmt.Errorf("%w: it can be closed in %s", ErrMinHoldTime, wait.Round(time.Millisecond))
+	}
+	return nil
+}
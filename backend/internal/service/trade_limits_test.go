@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"tradeoff/backend/internal/config"
+	"tradeoff/backend/internal/domain"
+)
+
+func TestCheckOrder(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		limits      config.LimitsConfig
+		trades      int
+		lastOrderAt time.Time
+		opens       bool
+		wantErr     error
+	}{
+		{name: "no limits", trades: 50, lastOrderAt: now, opens: true},
+		{name: "first order", limits: config.LimitsConfig{MaxTrades: 3, OrderCooldown: time.Second}, opens: true},
+		{name: "within the cooldown", limits: config.LimitsConfig{OrderCooldown: time.Second}, lastOrderAt: now.Add(-999 * time.Millisecond), wantErr: ErrOrderCooldown},
+		{name: "cooldown over", limits: config.LimitsConfig{OrderCooldown: time.Second}, lastOrderAt: now.Add(-time.Second)},
+		{name: "under the trade limit", limits: config.LimitsConfig{MaxTrades: 3}, trades: 2, opens: true},
+		{name: "at the trade limit", limits: config.LimitsConfig{MaxTrades: 3}, trades: 3, opens: true, wantErr: ErrTradeLimitReached},
+		{name: "closing at the trade limit", limits: config.LimitsConfig{MaxTrades: 3}, trades: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewPlayerService(config.TradingConfig{Limits: tt.limits}, NewTradeHistory(nil))
+			session := &domain.PlayerState{Trades: tt.trades, LastOrderAt: tt.lastOrderAt}
+			if err := s.checkOrderUnsafe(session, tt.opens, now); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("checkOrderUnsafe() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckHold(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		minHold time.Duration
+		held    time.Duration
+		wantErr error
+	}{
+		{name: "no minimum", held: 0},
+		{name: "held too briefly", minHold: 5 * time.Second, held: 4 * time.Second, wantErr: ErrMinHoldTime},
+		{name: "held long enough", minHold: 5 * time.Second, held: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewPlayerService(config.TradingConfig{Limits: config.LimitsConfig{MinHold: tt.minHold}}, NewTradeHistory(nil))
+			position := &domain.Position{EntryTime: now.Add(-tt.held)}
+			if err := s.checkHoldUnsafe(position, now); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("checkHoldUnsafe() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTradeLimitAccounting(t *testing.T) {
+	s := NewPlayerService(config.TradingConfig{Limits: config.LimitsConfig{MaxTrades: 3}}, NewTradeHistory(nil))
+	name := "alice"
+	s.GetPlayerSessionOrCreate(name, &name)
+	orders := NewOrderService(s)
+	size := PositionSize{Amount: 10}
+
+	remaining := func() int {
+		t.Helper()
+		left := s.TradesRemaining(name)
+		if left == nil {
+			t.Fatal("TradesRemaining() = nil, want a limit")
+		}
+		return *left
+	}
+
+	position, err := s.CreatePosition(name, domain.PositionTypeLong, 100, size, PositionExits{}, 1)
+	if err != nil {
+		t.Fatalf("CreatePosition: %v", err)
+	}
+	if _, err := s.CreatePosition(name, domain.PositionTypeLong, 100, PositionSize{Amount: 500}, PositionExits{}, 1); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("oversized CreatePosition error = %v, want %v", err, ErrInsufficientFunds)
+	}
+	if _, err := s.ClosePosition(name, position.ID, 100); err != nil {
+		t.Fatalf("ClosePosition: %v", err)
+	}
+	if left := remaining(); left != 2 {
+		t.Fatalf("after one open, a rejected open and a close %d trades are left, want 2", left)
+	}
+
+	order, err := orders.PlaceOrder(name, OrderRequest{Type: domain.OrderTypeLimit, Side: domain.PositionTypeLong, Price: 90, Size: size}, 100, 1)
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if left := remaining(); left != 1 {
+		t.Fatalf("a pending order leaves %d trades, want 1", left)
+	}
+	if _, err := orders.CancelOrder(name, order.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if left := remaining(); left != 2 {
+		t.Fatalf("a cancelled order leaves %d trades, want its trade given back", left)
+	}
+
+	if _, err := s.CreatePosition(name, domain.PositionTypeShort, 100, size, PositionExits{}, 1); err != nil {
+		t.Fatalf("CreatePosition: %v", err)
+	}
+	if _, _, err := s.ReversePosition(name, "", 100); err != nil {
+		t.Fatalf("ReversePosition: %v", err)
+	}
+	if left := remaining(); left != 0 {
+		t.Fatalf("after an open and a reverse %d trades are left, want 0", left)
+	}
+	if _, err := s.CreatePosition(name, domain.PositionTypeLong, 100, size, PositionExits{}, 1); !errors.Is(err, ErrTradeLimitReached) {
+		t.Fatalf("CreatePosition past the limit error = %v, want %v", err, ErrTradeLimitReached)
+	}
+	if _, err := s.ClosePosition(name, "", 100); err != nil {
+		t.Fatalf("closing at the trade limit: %v", err)
+	}
+
+	s.ResetAllPlayers()
+	if left := remaining(); left != 3 {
+		t.Fatalf("a new round starts with %d trades, want 3", left)
+	}
+}
+
+func TestNewTradeLimits(t *testing.T) {
+	limits := newTradeLimits(config.LimitsConfig{MaxTrades: -1, MinHold: 1500 * time.Millisecond, OrderCooldown: -time.Second})
+	if limits != (TradeLimits{MinHoldMs: 1500}) {
+		t.Fatalf("newTradeLimits() = %+v, want negative rules disabled and 1500ms of hold", limits)
+	}
+	if allowance := limits.allowance(); allowance != nil {
+		t.Fatalf("allowance() = %d, want nil without a trade limit", *allowance)
+	}
+	if allowance := (TradeLimits{MaxTrades: 4}).allowance(); allowance == nil || *allowance != 4 {
+		t.Fatalf("allowance() = %v, want 4", allowance)
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"fmt"
+	"tradeoff/backend/internal/domain"
+)
+
+// Quote is the displayed price trades fill at and the live bar it came from.
+type Quote struct {
+	Price float64
+	Tick  uint64
+}
+
+// Trade runs fn with the current quote. Bars are held back until fn returns,
+// so whatever fn fills is filled at the current tick's price. Trade fails with
+// ErrTradingClosed outside the Live phase, with ErrPriceUnavailable before the
+// round has a price, and with ErrStaleQuote if tick is set and is not the
+// current tick.
+func (r *RoundManager) Trade(tick uint64, fn func(Quote) error) error {
+	r.tradeMu.RLock()
+	defer r.tradeMu.RUnlock()
+
+	r.mu.RLock()
+	phase := r.phase
+	quote := Quote{
+		Price: r.currentPriceUnsafe(),
+		Tick:  r.tick,
+	}
+	r.mu.RUnlock()
+
+	if phase != domain.Live {
+		return fmt.Errorf("%w: round is in the %s phase", ErrTradingClosed, phase)
+	}
+	if quote.Price <= 0 {
+		return ErrPriceUnavailable
+	}
+	if tick != 0 && tick != quote.Tick {
+		return fmt.Errorf("%w: quote is from tick %d, current tick is %d", ErrStaleQuote, tick, quote.Tick)
+	}
+	return fn(quote)
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"tradeoff/backend/internal/domain"
+)
+
+func TestTrade(t *testing.T) {
+	errRejected := errors.New("rejected")
+	chart := []domain.PriceData{{Close: 90}, {Close: 100}}
+
+	tests := []struct {
+		name      string
+		phase     domain.Phase
+		chartData []domain.PriceData
+		tick      uint64
+		fnErr     error
+		wantErr   error
+		wantQuote Quote
+	}{
+		{name: "lobby is closed", phase: domain.Lobby, chartData: chart, wantErr: ErrTradingClosed},
+		{name: "closed round is closed", phase: domain.Closed, chartData: chart, wantErr: ErrTradingClosed},
+		{name: "no price yet", phase: domain.Live, wantErr: ErrPriceUnavailable},
+		{name: "any tick without one", phase: domain.Live, chartData: chart, wantQuote: Quote{Price: 200, Tick: 7}},
+		{name: "current tick", phase: domain.Live, chartData: chart, tick: 7, wantQuote: Quote{Price: 200, Tick: 7}},
+		{name: "stale tick", phase: domain.Live, chartData: chart, tick: 6, wantErr: ErrStaleQuote},
+		{name: "future tick", phase: domain.Live, chartData: chart, tick: 8, wantErr: ErrStaleQuote},
+		{name: "trade error is returned", phase: domain.Live, chartData: chart, fnErr: errRejected, wantErr: errRejected, wantQuote: Quote{Price: 200, Tick: 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &RoundManager{
+				phase:     tt.phase,
+				chartData: tt.chartData,
+				tick:      7,
+				disguise:  priceDisguise{priceScale: 2},
+			}
+
+			var called bool
+			var got Quote
+			err := r.Trade(tt.tick, func(quote Quote) error {
+				called, got = true, quote
+				if r.tradeMu.TryLock() {
+					t.Error("a bar could be applied while the trade was filling")
+				}
+				return tt.fnErr
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Trade error = %v, want %v", err, tt.wantErr)
+			}
+			wantCalled := tt.wantErr == nil || tt.fnErr != nil
+			if called != wantCalled {
+				t.Fatalf("trade ran = %v, want %v", called, wantCalled)
+			}
+			if called && got != tt.wantQuote {
+				t.Errorf("quote = %+v, want %+v", got, tt.wantQuote)
+			}
+		})
+	}
+}
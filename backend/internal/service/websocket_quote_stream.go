@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"log"
+	"tradeoff/backend/internal/domain"
+
+	"github.com/gorilla/websocket"
+)
+
+// quoteSubscribeRequest is the first message sent to a WebSocket quote feed.
+type quoteSubscribeRequest struct {
+	Action string `json:"action"`
+	Ticker string `json:"ticker"`
+}
+
+// WebSocketQuoteStream reads aggregates from a plain JSON WebSocket feed.
+// After connecting it sends {"action":"subscribe","ticker":"..."} and expects
+// every following message to be a domain.PriceData object. Any server that
+// speaks this protocol, including a local stub, can drive live rounds.
+type WebSocketQuoteStream struct {
+	url string
+}
+
+func NewWebSocketQuoteStream(url string) *WebSocketQuoteStream {
+	return &WebSocketQuoteStream{
+		url: url,
+	}
+}
+
+func (s *WebSocketQuoteStream) Subscribe(ctx context.Context, ticker string) (<-chan domain.PriceData, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.WriteJSON(quoteSubscribeRequest{Action: "subscribe", Ticker: ticker}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// Closing the connection unblocks ReadJSON once ctx is done.
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	bars := make(chan domain.PriceData, 64)
+	go func() {
+		defer close(bars)
+		defer conn.Close()
+
+		for {
+			var bar domain.PriceData
+			if err := conn.ReadJSON(&bar); err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Quote feed error for %s: %v", ticker, err)
+				}
+				return
+			}
+			select {
+			case bars <- bar:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return bars, nil
+}
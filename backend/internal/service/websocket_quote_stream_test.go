@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"tradeoff/backend/internal/domain"
+
+	"github.com/gorilla/websocket"
+)
+
+// quoteFeedStub is a WebSocket quote feed that records the subscribe request,
+// sends bars and then either hangs up, as a failing feed does, or waits for
+// the client to disconnect.
+type quoteFeedStub struct {
+	bars       []domain.PriceData
+	hangUp     bool
+	subscribes chan quoteSubscribeRequest
+}
+
+func (f *quoteFeedStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var upgrader websocket.Upgrader
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var req quoteSubscribeRequest
+	if err := conn.ReadJSON(&req); err != nil {
+		return
+	}
+	f.subscribes <- req
+
+	for _, bar := range f.bars {
+		if err := conn.WriteJSON(bar); err != nil {
+			return
+		}
+	}
+	if f.hangUp {
+		return
+	}
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// receiveBar returns the next bar from the stream, or false if it was closed.
+func receiveBar(t *testing.T, bars <-chan domain.PriceData) (domain.PriceData, bool) {
+	t.Helper()
+	select {
+	case bar, ok := <-bars:
+		return bar, ok
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting on the quote stream")
+		return domain.PriceData{}, false
+	}
+}
+
+func TestWebSocketQuoteStream(t *testing.T) {
+	sent := []domain.PriceData{
+		{Time: 1700000000, Open: 100, High: 101, Low: 99, Close: 100.5, Volume: 3},
+		{Time: 1700000060, Open: 100.5, High: 102, Low: 100, Close: 101.5, Volume: 4},
+	}
+
+	tests := []struct {
+		name   string
+		hangUp bool
+	}{
+		{name: "closes when ctx is cancelled"},
+		{name: "closes when the feed fails", hangUp: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed := &quoteFeedStub{bars: sent, hangUp: tt.hangUp, subscribes: make(chan quoteSubscribeRequest, 1)}
+			server := httptest.NewServer(feed)
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stream := NewWebSocketQuoteStream("ws" + strings.TrimPrefix(server.URL, "http"))
+			bars, err := stream.Subscribe(ctx, "X:BTCUSD")
+			if err != nil {
+				t.Fatalf("Subscribe: %v", err)
+			}
+
+			select {
+			case req := <-feed.subscribes:
+				if req != (quoteSubscribeRequest{Action: "subscribe", Ticker: "X:BTCUSD"}) {
+					t.Errorf("subscribe request = %+v, want subscribe to X:BTCUSD", req)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("feed never received a subscribe request")
+			}
+
+			for i, want := range sent {
+				bar, ok := receiveBar(t, bars)
+				if !ok {
+					t.Fatalf("stream closed after %d bars, want %d", i, len(sent))
+				}
+				if bar != want {
+					t.Errorf("bar %d = %+v, want %+v", i, bar, want)
+				}
+			}
+
+			if !tt.hangUp {
+				cancel()
+			}
+			if bar, ok := receiveBar(t, bars); ok {
+				t.Fatalf("stream delivered %+v, want it closed", bar)
+			}
+		})
+	}
+}
+
+func TestWebSocketQuoteStreamDialError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	stream := NewWebSocketQuoteStream("ws" + strings.TrimPrefix(server.URL, "http"))
+	if _, err := stream.Subscribe(context.Background(), "X:BTCUSD"); err == nil {
+		t.Fatal("Subscribe succeeded against a server that does not speak WebSocket")
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import "math/rand/v2"
+
+// pickWeighted draws one item with probability proportional to its weight.
+// Items with a non-positive weight are never picked. It reports false when no
+// item has a positive weight.
+func pickWeighted[T any](items []T, weight func(T) float64) (T, bool) {
+	var picked T
+	totalWeight := 0.0
+	for _, item := range items {
+		if w := weight(item); w > 0 {
+			totalWeight += w
+		}
+	}
+	if totalWeight == 0 {
+		return picked, false
+	}
+
+	target := rand.Float64() * totalWeight
+	for _, item := range items {
+		w := weight(item)
+		if w <= 0 {
+			continue
+		}
+		picked = item
+		target -= w
+		if target < 0 {
+			break
+		}
+	}
+	return picked, true
+}
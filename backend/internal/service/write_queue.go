@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// writeQueue feeds a background writer. It is unbounded, so pushing never
+// blocks or drops an item, and callers can record while holding their locks;
+// if the database falls behind the queue grows until it catches up.
+type writeQueue[T any] struct {
+	mu    sync.Mutex
+	items []T
+	ready chan struct{}
+}
+
+func newWriteQueue[T any]() *writeQueue[T] {
+	return &writeQueue[T]{ready: make(chan struct{}, 1)}
+}
+
+func (q *writeQueue[T]) push(item T) {
+	q.mu.Lock()
+	q.items = append(q.items, item)
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// take removes and returns everything queued.
+func (q *writeQueue[T]) take() []T {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := q.items
+	q.items = nil
+	return items
+}
+
+// run passes whatever has queued up to write, in order, until ctx is
+// cancelled. It then writes what is still queued before returning, so waiting
+// for run to return on shutdown loses nothing recorded before it.
+func (q *writeQueue[T]) run(ctx context.Context, write func([]T)) {
+	for {
+		select {
+		case <-ctx.Done():
+			if items := q.take(); len(items) > 0 {
+				write(items)
+			}
+			return
+		case <-q.ready:
+			if items := q.take(); len(items) > 0 {
+				write(items)
+			}
+		}
+	}
+}
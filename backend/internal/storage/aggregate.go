@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"time"
+	"tradeoff/backend/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (am *AggregateModel) ToDomain() domain.PriceData {
+	return domain.PriceData{
+		Time:   am.Time,
+		Open:   am.Open,
+		High:   am.High,
+		Low:    am.Low,
+		Close:  am.Close,
+		Volume: am.Volume,
+	}
+}
+
+func aggregateFromDomain(key domain.AggregateKey, bar domain.PriceData) AggregateModel {
+	return AggregateModel{
+		Ticker:     key.Ticker,
+		Multiplier: key.Multiplier,
+		Timespan:   string(key.Timespan),
+		Time:       bar.Time,
+		Open:       bar.Open,
+		High:       bar.High,
+		Low:        bar.Low,
+		Close:      bar.Close,
+		Volume:     bar.Volume,
+	}
+}
+
+func (s *PostgresStore) FindAggregates(key domain.AggregateKey, from time.Time, to time.Time) ([]domain.PriceData, error) {
+	var models []AggregateModel
+	err := s.DB.
+		Where("ticker = ? AND multiplier = ? AND timespan = ?", key.Ticker, key.Multiplier, string(key.Timespan)).
+		Where("time BETWEEN ? AND ?", from.Unix(), to.Unix()).
+		Order("time asc").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	priceData := make([]domain.PriceData, 0, len(models))
+	for _, model := range models {
+		priceData = append(priceData, model.ToDomain())
+	}
+	return priceData, nil
+}
+
+func (s *PostgresStore) FindAggregateRanges(key domain.AggregateKey) ([]domain.TimeRange, error) {
+	var models []AggregateRangeModel
+	err := s.DB.
+		Where("ticker = ? AND multiplier = ? AND timespan = ?", key.Ticker, key.Multiplier, string(key.Timespan)).
+		Order("\"from\" asc").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := make([]domain.TimeRange, 0, len(models))
+	for _, model := range models {
+		ranges = append(ranges, domain.TimeRange{From: model.From.UTC(), To: model.To.UTC()})
+	}
+	return ranges, nil
+}
+
+// SaveAggregates upserts the bars and records the covered range in one transaction,
+// so a range is never marked as cached without its bars.
+func (s *PostgresStore) SaveAggregates(key domain.AggregateKey, coverage domain.TimeRange, bars []domain.PriceData) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if len(bars) > 0 {
+			models := make([]AggregateModel, 0, len(bars))
+			for _, bar := range bars {
+				models = append(models, aggregateFromDomain(key, bar))
+			}
+			err := tx.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(&models, 500).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		rangeModel := AggregateRangeModel{
+			Ticker:     key.Ticker,
+			Multiplier: key.Multiplier,
+			Timespan:   string(key.Timespan),
+			From:       coverage.From.UTC(),
+			To:         coverage.To.UTC(),
+		}
+		return tx.Create(&rangeModel).Error
+	})
+}
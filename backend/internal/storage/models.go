@@ -18,3 +18,107 @@ type PlayerModel struct {
 func (PlayerModel) TableName() string {
 	return "players"
 }
+
+// RoundModel represents a played round in the rounds table
+type RoundModel struct {
+	ID               string     `gorm:"type:varchar(36);primaryKey"`
+	Type             string     `gorm:"type:varchar(16);not null"`
+	AssetTicker      string     `gorm:"type:varchar(32);not null"`
+	AssetName        string     `gorm:"type:varchar(255);not null"`
+	AssetClass       string     `gorm:"type:varchar(16);not null"`
+	Synthetic        bool       `gorm:"not null"`
+	Seed             int64      `gorm:"not null"`
+	CandleTimeframe  string     `gorm:"type:varchar(8);not null"`
+	ReplayResolution string     `gorm:"type:varchar(8);not null"`
+	From             time.Time  `gorm:"type:timestamp;not null"`
+	To               time.Time  `gorm:"type:timestamp;not null"`
+	Phase            string     `gorm:"type:varchar(16);not null;index"`
+	LobbyAt          time.Time  `gorm:"type:timestamp;not null;index"`
+	LiveAt           *time.Time `gorm:"type:timestamp"`
+	ClosedAt         *time.Time `gorm:"type:timestamp"`
+	FinalPrice       float64    `gorm:"not null"`
+	PriceScale       float64    `gorm:"not null"`
+	Participants     int        `gorm:"not null"`
+}
+
+// TableName specifies the table name for GORM
+func (RoundModel) TableName() string {
+	return "rounds"
+}
+
+// TradeModel represents an opened or closed position in the trades table
+type TradeModel struct {
+	ID          string    `gorm:"type:varchar(36);primaryKey"`
+	PlayerID    string    `gorm:"type:varchar(36);not null;index:idx_trades_player_time"`
+	RoundID     string    `gorm:"type:varchar(36);not null;index"`
+	PositionID  string    `gorm:"type:varchar(36);not null"`
+	Action      string    `gorm:"type:varchar(8);not null"`
+	Type        string    `gorm:"type:varchar(8);not null"`
+	Price       float64   `gorm:"not null"`
+	Quantity    float64   `gorm:"not null"`
+	Leverage    float64   `gorm:"not null"`
+	Margin      float64   `gorm:"not null"`
+	Fees        float64   `gorm:"not null"`
+	Funding     float64   `gorm:"not null"`
+	Pnl         float64   `gorm:"not null"`
+	CloseReason string    `gorm:"type:varchar(16)"`
+	Time        time.Time `gorm:"type:timestamp;not null;index:idx_trades_player_time"`
+}
+
+// TableName specifies the table name for GORM
+func (TradeModel) TableName() string {
+	return "trades"
+}
+
+// RoundResultModel represents a player's result for one round in the round_results table
+type RoundResultModel struct {
+	RoundID          string    `gorm:"type:varchar(36);primaryKey"`
+	PlayerID         string    `gorm:"type:varchar(36);primaryKey;index:idx_round_results_player_settled"`
+	Username         string    `gorm:"type:varchar(255);not null"`
+	Rank             int       `gorm:"not null"`
+	Balance          float64   `gorm:"not null"`
+	Pnl              float64   `gorm:"not null"`
+	ReturnPercentage float64   `gorm:"not null"`
+	Trades           int       `gorm:"not null"`
+	Players          int       `gorm:"not null"`
+	SettledAt        time.Time `gorm:"type:timestamp;not null;index:idx_round_results_player_settled"`
+}
+
+// TableName specifies the table name for GORM
+func (RoundResultModel) TableName() string {
+	return "round_results"
+}
+
+// AggregateModel represents a cached OHLCV bar in the market_aggregates table
+type AggregateModel struct {
+	Ticker     string  `gorm:"type:varchar(32);primaryKey"`
+	Multiplier int     `gorm:"primaryKey"`
+	Timespan   string  `gorm:"type:varchar(16);primaryKey"`
+	Time       int64   `gorm:"primaryKey"`
+	Open       float64 `gorm:"not null"`
+	High       float64 `gorm:"not null"`
+	Low        float64 `gorm:"not null"`
+	Close      float64 `gorm:"not null"`
+	Volume     float64 `gorm:"not null"`
+}
+
+// TableName specifies the table name for GORM
+func (AggregateModel) TableName() string {
+	return "market_aggregates"
+}
+
+// AggregateRangeModel records a time range that has been fully fetched for a series
+type AggregateRangeModel struct {
+	ID         uint      `gorm:"primaryKey"`
+	Ticker     string    `gorm:"type:varchar(32);not null;index:idx_aggregate_range_series"`
+	Multiplier int       `gorm:"not null;index:idx_aggregate_range_series"`
+	Timespan   string    `gorm:"type:varchar(16);not null;index:idx_aggregate_range_series"`
+	From       time.Time `gorm:"type:timestamp;not null"`
+	To         time.Time `gorm:"type:timestamp;not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (AggregateRangeModel) TableName() string {
+	return "market_aggregate_ranges"
+}
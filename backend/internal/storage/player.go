@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"errors"
 	"tradeoff/backend/internal/domain"
 
 	"gorm.io/gorm"
@@ -89,15 +90,16 @@ func (s *PostgresStore) UpdatePlayer(player domain.Player) (domain.Player, error
 	return playerModel.ToDomain(), nil
 }
 
-func (s *PostgresStore) GetPlayer(id string) (domain.Player, error) {
+func (s *PostgresStore) FindPlayer(id string) (*domain.Player, error) {
 	var playerModel PlayerModel
 	err := s.DB.Where("id = ?", id).First(&playerModel).Error
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return domain.Player{}, gorm.ErrRecordNotFound
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
 		}
-		return domain.Player{}, err
+		return nil, err
 	}
 
-	return playerModel.ToDomain(), nil
+	player := playerModel.ToDomain()
+	return &player, nil
 }
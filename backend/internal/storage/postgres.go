@@ -45,5 +45,10 @@ func NewPostgresStore(config config.Config) (*PostgresStore, error) {
 func (s *PostgresStore) AutoMigrate() error {
 	return s.DB.AutoMigrate(
 		&PlayerModel{},
+		&AggregateModel{},
+		&AggregateRangeModel{},
+		&RoundModel{},
+		&TradeModel{},
+		&RoundResultModel{},
 	)
 }
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"errors"
+	"time"
+	"tradeoff/backend/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (rm *RoundModel) ToDomain() domain.Round {
+	return domain.Round{
+		ID:   rm.ID,
+		Type: domain.RoundType(rm.Type),
+		Asset: domain.Asset{
+			Ticker: rm.AssetTicker,
+			Name:   rm.AssetName,
+			Class:  domain.AssetClass(rm.AssetClass),
+		},
+		Synthetic:        rm.Synthetic,
+		Seed:             uint64(rm.Seed),
+		CandleTimeframe:  rm.CandleTimeframe,
+		ReplayResolution: rm.ReplayResolution,
+		From:             rm.From.UTC(),
+		To:               rm.To.UTC(),
+		Phase:            domain.Phase(rm.Phase),
+		LobbyAt:          rm.LobbyAt.UTC(),
+		LiveAt:           utcPtr(rm.LiveAt),
+		ClosedAt:         utcPtr(rm.ClosedAt),
+		FinalPrice:       rm.FinalPrice,
+		PriceScale:       rm.PriceScale,
+		Participants:     rm.Participants,
+	}
+}
+
+func roundFromDomain(round domain.Round) RoundModel {
+	return RoundModel{
+		ID:          round.ID,
+		Type:        string(round.Type),
+		AssetTicker: round.Asset.Ticker,
+		AssetName:   round.Asset.Name,
+		AssetClass:  string(round.Asset.Class),
+		Synthetic:   round.Synthetic,
+		// Postgres has no unsigned integers; the seed round-trips bit for bit.
+		Seed:             int64(round.Seed),
+		CandleTimeframe:  round.CandleTimeframe,
+		ReplayResolution: round.ReplayResolution,
+		From:             round.From.UTC(),
+		To:               round.To.UTC(),
+		Phase:            string(round.Phase),
+		LobbyAt:          round.LobbyAt.UTC(),
+		LiveAt:           utcPtr(round.LiveAt),
+		ClosedAt:         utcPtr(round.ClosedAt),
+		FinalPrice:       round.FinalPrice,
+		PriceScale:       round.PriceScale,
+		Participants:     round.Participants,
+	}
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// SaveRound inserts the round or replaces its stored record.
+func (s *PostgresStore) SaveRound(round domain.Round) error {
+	model := roundFromDomain(round)
+	return s.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&model).Error
+}
+
+func (s *PostgresStore) FindRound(id string) (*domain.Round, error) {
+	var model RoundModel
+	err := s.DB.Where("id = ?", id).First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	round := model.ToDomain()
+	return &round, nil
+}
+
+func (s *PostgresStore) ListRounds(phase domain.Phase, limit int, offset int) ([]domain.Round, error) {
+	var models []RoundModel
+	err := s.DB.
+		Where("phase = ?", string(phase)).
+		Order("lobby_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rounds := make([]domain.Round, 0, len(models))
+	for _, model := range models {
+		rounds = append(rounds, model.ToDomain())
+	}
+	return rounds, nil
+}
+
+func (rm *RoundResultModel) ToDomain() domain.RoundResult {
+	return domain.RoundResult{
+		RoundID: rm.RoundID,
+		PlayerResult: domain.PlayerResult{
+			PlayerId:         rm.PlayerID,
+			Username:         rm.Username,
+			Rank:             rm.Rank,
+			Balance:          rm.Balance,
+			Pnl:              rm.Pnl,
+			ReturnPercentage: rm.ReturnPercentage,
+			Trades:           rm.Trades,
+		},
+		Players:   rm.Players,
+		SettledAt: rm.SettledAt.UTC(),
+	}
+}
+
+func roundResultFromDomain(result domain.RoundResult) RoundResultModel {
+	return RoundResultModel{
+		RoundID:          result.RoundID,
+		PlayerID:         result.PlayerId,
+		Username:         result.Username,
+		Rank:             result.Rank,
+		Balance:          result.Balance,
+		Pnl:              result.Pnl,
+		ReturnPercentage: result.ReturnPercentage,
+		Trades:           result.Trades,
+		Players:          result.Players,
+		SettledAt:        result.SettledAt.UTC(),
+	}
+}
+
+// SaveRoundResults inserts the results of a round, replacing any already stored.
+func (s *PostgresStore) SaveRoundResults(results []domain.RoundResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	models := make([]RoundResultModel, 0, len(results))
+	for _, result := range results {
+		models = append(models, roundResultFromDomain(result))
+	}
+	return s.DB.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(&models, 500).Error
+}
+
+func (s *PostgresStore) ListRoundResults(playerID string) ([]domain.RoundResult, error) {
+	var models []RoundResultModel
+	err := s.DB.
+		Where("player_id = ?", playerID).
+		Order("settled_at asc").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.RoundResult, 0, len(models))
+	for _, model := range models {
+		results = append(results, model.ToDomain())
+	}
+	return results, nil
+}
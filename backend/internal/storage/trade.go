@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"tradeoff/backend/internal/domain"
+)
+
+func (tm *TradeModel) ToDomain() domain.Trade {
+	return domain.Trade{
+		ID:          tm.ID,
+		PlayerID:    tm.PlayerID,
+		RoundID:     tm.RoundID,
+		PositionID:  tm.PositionID,
+		Action:      domain.TradeAction(tm.Action),
+		Type:        domain.PositionType(tm.Type),
+		Price:       tm.Price,
+		Quantity:    tm.Quantity,
+		Leverage:    tm.Leverage,
+		Margin:      tm.Margin,
+		Fees:        tm.Fees,
+		Funding:     tm.Funding,
+		Pnl:         tm.Pnl,
+		CloseReason: domain.CloseReason(tm.CloseReason),
+		Time:        tm.Time.UTC(),
+	}
+}
+
+func tradeFromDomain(trade domain.Trade) TradeModel {
+	return TradeModel{
+		ID:          trade.ID,
+		PlayerID:    trade.PlayerID,
+		RoundID:     trade.RoundID,
+		PositionID:  trade.PositionID,
+		Action:      string(trade.Action),
+		Type:        string(trade.Type),
+		Price:       trade.Price,
+		Quantity:    trade.Quantity,
+		Leverage:    trade.Leverage,
+		Margin:      trade.Margin,
+		Fees:        trade.Fees,
+		Funding:     trade.Funding,
+		Pnl:         trade.Pnl,
+		CloseReason: string(trade.CloseReason),
+		Time:        trade.Time.UTC(),
+	}
+}
+
+func (s *PostgresStore) SaveTrades(trades []domain.Trade) error {
+	if len(trades) == 0 {
+		return nil
+	}
+	models := make([]TradeModel, 0, len(trades))
+	for _, trade := range trades {
+		models = append(models, tradeFromDomain(trade))
+	}
+	return s.DB.CreateInBatches(&models, 500).Error
+}
+
+func (s *PostgresStore) ListTrades(playerID string, limit int, offset int) ([]domain.Trade, error) {
+	var models []TradeModel
+	err := s.DB.
+		Where("player_id = ?", playerID).
+		Order("time desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]domain.Trade, 0, len(models))
+	for _, model := range models {
+		trades = append(trades, model.ToDomain())
+	}
+	return trades, nil
+}
+
+func (s *PostgresStore) TallyClosedTrades(playerID string) (domain.TradeTally, domain.TradeTally, error) {
+	var rows []struct {
+		Type   string
+		Trades int
+		Wins   int
+	}
+	err := s.DB.Model(&TradeModel{}).
+		Select("type, count(*) AS trades, count(*) FILTER (WHERE pnl > 0) AS wins").
+		Where("player_id = ? AND action = ?", playerID, string(domain.TradeActionClose)).
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return domain.TradeTally{}, domain.TradeTally{}, err
+	}
+
+	var long, short domain.TradeTally
+	for _, row := range rows {
+		tally := domain.TradeTally{Trades: row.Trades, Wins: row.Wins}
+		if domain.PositionType(row.Type) == domain.PositionTypeShort {
+			short = tally
+		} else {
+			long = tally
+		}
+	}
+	return long, short, nil
+}
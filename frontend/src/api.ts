@@ -65,13 +65,14 @@ class ApiService {
         return response.json();
     }
 
-    async closePosition(): Promise<void> {
+    async closePosition(positionId: string): Promise<void> {
         const response = await fetch(`${this.apiUrl}/api/close-position`, {
             method: "POST",
             headers: {
                 "Content-Type": "application/json",
                 "Authorization": `Bearer ${this.token}`,
             },
+            body: JSON.stringify({ positionId }),
         });
         
         if (!response.ok) {
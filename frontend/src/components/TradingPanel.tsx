@@ -17,29 +17,27 @@ export default function TradingPanel() {
     balance,
     totalRealizedPnl,
     totalUnrealizedPnl,
-    activePosition,
+    unrealizedPnlPercentage,
+    activePositions,
     phase,
     handleTrade,
     handleClosePosition,
   } = useGameStore();
 
-  const [duration, setDuration] = useState(0);
+  const [now, setNow] = useState(Date.now());
 
-  // Update duration every second when there's an active position
+  // Tick every second while positions are open to update their durations
   useEffect(() => {
-    if (!activePosition) {
-      setDuration(0);
+    if (activePositions.length === 0) {
       return;
     }
 
-    const interval = setInterval(() => {
-      const entryTime = new Date(activePosition.entryTime).getTime();
-      const now = Date.now();
-      setDuration(Math.floor((now - entryTime) / 1000));
-    }, 1000);
+    const interval = setInterval(() => setNow(Date.now()), 1000);
 
     return () => clearInterval(interval);
-  }, [activePosition]);
+  }, [activePositions.length]);
+
+  const risk = getRiskLevel(unrealizedPnlPercentage).level;
 
   return (
     <div className="bg-gray-900/80 backdrop-blur-sm rounded-xl border border-gray-700/30 p-6 shadow-xl">
@@ -64,11 +62,22 @@ export default function TradingPanel() {
             value={formatCurrency(totalUnrealizedPnl)}
             valueColor={getPnlColor(totalUnrealizedPnl)}
           />
+
+          {activePositions.length > 0 && (
+            <StatCard
+              label="Risk"
+              value={risk}
+              valueColor={
+                risk === "HIGH" ? "danger" : risk === "MED" ? "warning" : "success"
+              }
+              className="text-xs font-semibold"
+            />
+          )}
         </div>
 
         {/* Trading Controls */}
         <div className="flex items-center gap-4">
-          {phase === "live" && !activePosition && (
+          {phase === "live" && (
             <>
               <TradingButton type="long" onClick={() => handleTrade("long")} />
               <TradingButton
@@ -77,10 +86,6 @@ export default function TradingPanel() {
               />
             </>
           )}
-
-          {phase === "live" && activePosition && (
-            <TradingButton type="close" onClick={handleClosePosition} />
-          )}
         </div>
 
         {/* Game Phase */}
@@ -90,51 +95,58 @@ export default function TradingPanel() {
           valueColor={getPhaseColor(phase)}
           className="font-semibold"
         />
+      </div>
 
-        {/* Active Position */}
-        {activePosition && (
-          <div className="flex items-center gap-6">
-            <StatCard
-              label="Position"
-              value={activePosition.type.toUpperCase()}
-              valueColor={activePosition.type === "long" ? "success" : "danger"}
-              className="font-semibold"
-            />
-
-            <StatCard
-              label="Entry Price"
-              value={formatCurrency(activePosition.entryPrice)}
-            />
-
-            <StatCard
-              label="P&L"
-              value={formatCurrency(activePosition.pnl)}
-              valueColor={getPnlColor(activePosition.pnl)}
-            />
+      {/* Active Positions */}
+      {activePositions.length > 0 && (
+        <div className="mt-4 flex flex-col gap-3">
+          {activePositions.map((position) => (
+            <div key={position.id} className="flex items-center gap-6">
+              <StatCard
+                label="Position"
+                value={position.type.toUpperCase()}
+                valueColor={position.type === "long" ? "success" : "danger"}
+                className="font-semibold"
+              />
 
-            <StatCard
-              label="P&L %"
-              value={formatPercentage(activePosition.pnlPercentage)}
-              valueColor={getPnlColor(activePosition.pnlPercentage)}
-            />
+              <StatCard
+                label="Entry Price"
+                value={formatCurrency(position.entryPrice)}
+              />
 
-            <StatCard label="Duration" value={formatDuration(duration)} />
+              {activePositions.length === 1 && (
+                <>
+                  <StatCard
+                    label="P&L"
+                    value={formatCurrency(position.pnl)}
+                    valueColor={getPnlColor(position.pnl)}
+                  />
+
+                  <StatCard
+                    label="P&L %"
+                    value={formatPercentage(position.pnlPercentage)}
+                    valueColor={getPnlColor(position.pnlPercentage)}
+                  />
+                </>
+              )}
+
+              <StatCard
+                label="Duration"
+                value={formatDuration(
+                  Math.max(0, Math.floor((now - new Date(position.entryTime).getTime()) / 1000))
+                )}
+              />
 
-            <StatCard
-              label="Risk"
-              value={getRiskLevel(activePosition.pnlPercentage).level}
-              valueColor={
-                getRiskLevel(activePosition.pnlPercentage).level === "HIGH"
-                  ? "danger"
-                  : getRiskLevel(activePosition.pnlPercentage).level === "MED"
-                  ? "warning"
-                  : "success"
-              }
-              className="text-xs font-semibold"
-            />
-          </div>
-        )}
-      </div>
+              {phase === "live" && (
+                <TradingButton
+                  type="close"
+                  onClick={() => handleClosePosition(position.id)}
+                />
+              )}
+            </div>
+          ))}
+        </div>
+      )}
     </div>
   );
 }
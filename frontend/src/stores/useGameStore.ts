@@ -1,6 +1,6 @@
 import { CandlestickData } from "lightweight-charts";
 import { create } from "zustand";
-import { GamePhase, Position, ClosedPosition, WebSocketMessage, PnlData, PhaseData, CountData, GameStateData, PriceUpdateData, PositionType, LeaderboardPlayer } from "@/types";
+import { GamePhase, Position, ClosedPosition, WebSocketMessage, PnlData, PhaseData, CountData, GameStateData, PriceUpdateData, PositionType, LeaderboardPlayer, ClosedByServerData } from "@/types";
 import apiService from "@/api";
 
 type GameStore = {
@@ -12,15 +12,16 @@ type GameStore = {
     shortPositions:  number                     
     totalPlayers:    number                     
     balance:         number                
-    activePosition:  Position | null
+    activePositions: Position[]
     closedPositions: ClosedPosition[] 
     totalRealizedPnl: number
     totalUnrealizedPnl: number
+    unrealizedPnlPercentage: number
     leaderboardData: LeaderboardPlayer[] | null
 
     // actions
     handleTrade: (positionType: PositionType) => void
-    handleClosePosition: () => void
+    handleClosePosition: (positionId: string) => void
     handleWSMessage: (msg: WebSocketMessage) => void
 }
 
@@ -33,23 +34,26 @@ export const useGameStore = create<GameStore>((set, get) => ({
     shortPositions: 0,
     totalPlayers: 0,
     balance: 0,
-    activePosition: null,
+    activePositions: [],
     closedPositions: [],
     totalRealizedPnl: 0,
     totalUnrealizedPnl: 0,
+    unrealizedPnlPercentage: 0,
     leaderboardData: null,
     handleTrade: async (positionType: PositionType) => {
         try {
             const position = await apiService.createPosition(positionType)
-            set({ activePosition: position })
+            set((state) => ({ activePositions: [...state.activePositions, position] }))
         } catch (error) {
             console.error("Error creating position:", error);
         }
     },
-    handleClosePosition: async () => {
+    handleClosePosition: async (positionId: string) => {
         try {
-            await apiService.closePosition()
-            set({ activePosition: null })
+            await apiService.closePosition(positionId)
+            set((state) => ({
+                activePositions: state.activePositions.filter((position) => position.id !== positionId),
+            }))
         } catch (error) {
             console.error("Error closing position:", error);
         }
@@ -72,19 +76,35 @@ export const useGameStore = create<GameStore>((set, get) => ({
                 break;
             case "pnl_update": {
                 const data = msg.data as PnlData;
-                const activePosition = get().activePosition
-                if (activePosition) {
-                    activePosition.pnl = data.activePnl
-                    activePosition.pnlPercentage = data.activePnlPercentage
+                // The update only carries the total across open positions,
+                // which is a single position's own P&L when only one is open.
+                let activePositions = get().activePositions
+                if (activePositions.length === 1) {
+                    activePositions = [{
+                        ...activePositions[0],
+                        pnl: data.activePnl,
+                        pnlPercentage: data.activePnlPercentage,
+                    }]
                 }
                 set({
                     totalRealizedPnl: data.pnl,
                     totalUnrealizedPnl: data.activePnl,
+                    unrealizedPnlPercentage: data.activePnlPercentage,
                     balance: data.balance,
-                    activePosition: activePosition,
+                    activePositions: activePositions,
                 });
                 break;
             }
+            case "liquidation":
+            case "order_filled": {
+                const data = msg.data as ClosedByServerData;
+                set((state) => ({
+                    balance: data.balance,
+                    activePositions: state.activePositions.filter((position) => position.id !== data.position.id),
+                    closedPositions: [...state.closedPositions, data.position],
+                }));
+                break;
+            }
             case "phase_update": {
                 const data = msg.data as PhaseData;
                 set({
@@ -118,10 +138,11 @@ export const useGameStore = create<GameStore>((set, get) => ({
                     phase: data.phase,
                     endTime: new Date(data.endTime),
                     balance: data.balance,
-                    activePosition: data.activePosition,
+                    activePositions: data.activePositions ?? [],
                     closedPositions: data.closedPositions,
                     totalRealizedPnl: data.pnl,
                     totalUnrealizedPnl: data.activePnl,
+                    unrealizedPnlPercentage: data.activePnlPercentage,
                     longPositions: data.longPositions,
                     shortPositions: data.shortPositions,
                     totalPlayers: data.totalPlayers,
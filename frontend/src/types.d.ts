@@ -35,12 +35,14 @@ export interface CountData {
 
 export interface BasePlayerState {
     balance: number;
-    activePosition: Position | null;
+    activePositions: Position[];
     closedPositions: ClosedPosition[];
 }
 
 export interface Position {
+    id: string;
     type: PositionType;
+    leverage: number;
     entryPrice: number;
     entryTime: string; 
     pnl: number;
@@ -52,6 +54,13 @@ export interface ClosedPosition extends Position {
     exitTime: string;
 }
 
+// Sent when a position is closed by the server: liquidated, or filled at its
+// stop-loss, take-profit or trailing stop.
+export interface ClosedByServerData {
+    position: ClosedPosition;
+    balance: number;
+}
+
 export interface GameStateData extends PhaseData, CountData, PnlData, BasePlayerState {
     roundId: string;
     chartData: CandlestickData[];
@@ -70,6 +79,6 @@ export interface LeaderboardPlayer {
 }
 
 export interface WebSocketMessage  {
-    type:  "price_update" | "pnl_update" | "phase_update" | "count_update" | "game_state_sync"| "new_round" | "leaderboard_update";
-    data: PriceUpdateData | PnlData | PhaseData | CountData | GameStateData | LeaderboardPlayer[]
+    type:  "price_update" | "pnl_update" | "phase_update" | "count_update" | "game_state_sync"| "new_round" | "leaderboard_update" | "liquidation" | "order_filled";
+    data: PriceUpdateData | PnlData | PhaseData | CountData | GameStateData | LeaderboardPlayer[] | ClosedByServerData
 }